@@ -0,0 +1,135 @@
+package board
+
+// direction is a single step across the board expressed as a change of file
+// and rank.
+type direction struct {
+	file int
+	rank int
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var orthagonalDirections = []direction{{0, 1}, {0, -1}, {1, 0}, {-1, 0}}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var diagonalDirections = []direction{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// step returns the square one step from sqr in the given direction, or 0 if
+// that would leave the board.
+func step(sqr Square, dir direction) Square {
+	idx := sqr.Index()
+	//nolint:gomnd // 8 squares to a rank
+	file := idx%8 + dir.file
+	//nolint:gomnd // 8 squares to a rank
+	rank := idx/8 + dir.rank
+
+	//nolint:gomnd // 8 squares to a rank
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return 0
+	}
+
+	//nolint:gomnd // 8 squares to a rank
+	return SquareAt(rank*8 + file)
+}
+
+// RookTargets returns the squares a rook on src can move to or capture on,
+// taking into account pieces blocking its path. The colour of the piece on
+// the final square of each ray is not considered.
+func (b *Board) RookTargets(src Square) []Square {
+	attacks := RookAttacks(src, b.Occupancy())
+
+	return attacks.Squares()
+}
+
+// BishopTargets returns the squares a bishop on src can move to or capture on,
+// taking into account pieces blocking its path. The colour of the piece on
+// the final square of each ray is not considered.
+func (b *Board) BishopTargets(src Square) []Square {
+	attacks := BishopAttacks(src, b.Occupancy())
+
+	return attacks.Squares()
+}
+
+// QueenTargets returns the squares a queen on src can move to or capture on,
+// taking into account pieces blocking its path. The colour of the piece on
+// the final square of each ray is not considered.
+func (b *Board) QueenTargets(src Square) []Square {
+	attacks := QueenAttacks(src, b.Occupancy())
+
+	return attacks.Squares()
+}
+
+// sidePieces returns each of side's sets of pieces.
+func (b *Board) sidePieces(side Side) (*King, *Queens, *Rooks, *Bishops, *Knights, *Pawns) {
+	if side == White {
+		return b.WhiteKing, b.WhiteQueens, b.WhiteRooks, b.WhiteBishops, b.WhiteKnights, b.WhitePawns
+	}
+
+	return b.BlackKing, b.BlackQueens, b.BlackRooks, b.BlackBishops, b.BlackKnights, b.BlackPawns
+}
+
+// AttackersTo returns the squares of the attacking side's pieces which attack
+// sqr.
+func (b *Board) AttackersTo(sqr Square, attackingSide Side) BitBoard {
+	return b.attackersTo(sqr, attackingSide, b.Occupancy())
+}
+
+// attackersTo finds the attackers of sqr as if only the occupied squares held
+// pieces, so sliders can be seen through pieces which have been taken off.
+func (b *Board) attackersTo(sqr Square, attackingSide Side, occupied BitBoard) BitBoard {
+	king, queens, rooks, bishops, knights, pawns := b.sidePieces(attackingSide)
+
+	// pawns attacking sqr are on the squares a pawn of the other colour
+	// on sqr would capture on.
+	pawnSide := other(attackingSide)
+
+	orthagonal := rooks.BitBoard.Board | queens.BitBoard.Board
+	diagonal := bishops.BitBoard.Board | queens.BitBoard.Board
+
+	return BitBoard{
+		Board: occupied.Board & (pawns.BitBoard.Board&leapers.pawn[pawnSide][sqr.Index()] |
+			knights.BitBoard.Board&leapers.knight[sqr.Index()] |
+			king.BitBoard.Board&leapers.king[sqr.Index()] |
+			orthagonal&RookAttacks(sqr, occupied).Board |
+			diagonal&BishopAttacks(sqr, occupied).Board),
+	}
+}
+
+// Attacked returns true if any of the attacking side's pieces attack sqr.
+func (b *Board) Attacked(sqr Square, attackingSide Side) bool {
+	return b.AttackersTo(sqr, attackingSide).Board != 0
+}
+
+// AttackMap returns every square attacked by side's pieces, whether or not
+// side could legally move there.
+func (b *Board) AttackMap(side Side) BitBoard {
+	return b.attackMap(side, b.Occupancy())
+}
+
+// attackMap finds the squares side attacks as if only the occupied squares
+// held pieces.
+func (b *Board) attackMap(side Side, occupied BitBoard) BitBoard {
+	king, queens, rooks, bishops, knights, pawns := b.sidePieces(side)
+	attacks := BitBoard{}
+
+	for piece := *king.BitBoard; piece.Board != 0; {
+		attacks.Board |= KingAttacks(piece.PopSquare()).Board
+	}
+
+	for piece := *knights.BitBoard; piece.Board != 0; {
+		attacks.Board |= KnightAttacks(piece.PopSquare()).Board
+	}
+
+	for piece := *pawns.BitBoard; piece.Board != 0; {
+		attacks.Board |= PawnAttacks(side, piece.PopSquare()).Board
+	}
+
+	for piece := (BitBoard{Board: rooks.BitBoard.Board | queens.BitBoard.Board}); piece.Board != 0; {
+		attacks.Board |= RookAttacks(piece.PopSquare(), occupied).Board
+	}
+
+	for piece := (BitBoard{Board: bishops.BitBoard.Board | queens.BitBoard.Board}); piece.Board != 0; {
+		attacks.Board |= BishopAttacks(piece.PopSquare(), occupied).Board
+	}
+
+	return attacks
+}
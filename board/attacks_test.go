@@ -0,0 +1,134 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // convey testing is verbose
+func TestSlidingTargets(t *testing.T) {
+	Convey("Given a board with blocking pieces", t, func() {
+		testBoard := board.NewBoard()
+		So(testBoard.SetPieces("4k3/8/8/1p6/8/8/1P1R2n1/4K3"), ShouldBeNil)
+		Convey("RookTargets() should stop at the first occupied square in each direction", func() {
+			targets := testBoard.RookTargets(board.D2)
+			So(targets, ShouldHaveLength, 12)
+			So(targets, ShouldContain, board.C2)
+			So(targets, ShouldContain, board.B2)
+			So(targets, ShouldNotContain, board.A2)
+			So(targets, ShouldContain, board.G2)
+			So(targets, ShouldNotContain, board.H2)
+			So(targets, ShouldContain, board.D1)
+			So(targets, ShouldContain, board.D8)
+		})
+		Convey("BishopTargets() should stop at the first occupied square in each direction", func() {
+			targets := testBoard.BishopTargets(board.D3)
+			So(targets, ShouldContain, board.B5)
+			So(targets, ShouldNotContain, board.A6)
+			So(targets, ShouldContain, board.E2)
+			So(targets, ShouldContain, board.F1)
+			So(targets, ShouldContain, board.H7)
+			So(targets, ShouldContain, board.C2)
+			So(targets, ShouldContain, board.B1)
+		})
+		Convey("QueenTargets() should combine both", func() {
+			So(testBoard.QueenTargets(board.D3), ShouldHaveLength,
+				len(testBoard.RookTargets(board.D3))+len(testBoard.BishopTargets(board.D3)))
+		})
+	})
+}
+
+func TestAttacked(t *testing.T) {
+	Convey("Given a board", t, func() {
+		testBoard := board.NewBoard()
+		Convey("Attacked() should return true if the square is attacked by the given side", func() {
+			testCases := []struct {
+				pieces string
+				sqr    board.Square
+			}{
+				{"k7/8/8/8/8/8/8/R1K5", board.A8},
+				{"k7/2N5/8/8/8/8/8/K7", board.A8},
+				{"k7/8/8/8/8/8/8/K6B", board.A8},
+				{"k7/8/8/8/8/8/8/Q1K5", board.A8},
+				{"k7/8/8/8/8/8/8/K6Q", board.A8},
+				{"k7/1P6/8/8/8/8/8/K7", board.A8},
+				{"k7/1K6/8/8/8/8/8/8", board.A8},
+				{"k7/8/8/8/8/8/8/K7", board.B2},
+				{"k7/8/8/8/3P4/8/8/K7", board.E5},
+				{"k7/8/8/8/8/8/8/K4R2", board.F7},
+				{"k7/8/8/8/8/8/8/K6B", board.C6},
+				{"k7/8/8/8/8/8/6Q1/K7", board.B7},
+				{"k7/8/8/8/8/8/6Q1/K7", board.G8},
+			}
+			for _, tc := range testCases {
+				So(testBoard.SetPieces(tc.pieces), ShouldBeNil)
+				So(testBoard.Attacked(tc.sqr, board.White), ShouldBeTrue)
+			}
+		})
+		Convey("Attacked() should return false if the square is not attacked by the given side", func() {
+			testCases := []struct {
+				pieces string
+				sqr    board.Square
+			}{
+				{"k7/8/8/8/8/8/8/1RK5", board.A8},
+				{"k7/8/8/3P4/8/8/8/K6B", board.A8},
+				{"k7/8/8/8/8/8/8/2K5", board.A8},
+				{"k7/P7/8/8/8/8/8/K7", board.A8},
+				{"k7/8/8/8/3P4/8/8/K7", board.D5},
+				{"k7/8/8/8/8/8/8/2R4K", board.B2},
+			}
+			for _, tc := range testCases {
+				So(testBoard.SetPieces(tc.pieces), ShouldBeNil)
+				So(testBoard.Attacked(tc.sqr, board.White), ShouldBeFalse)
+			}
+		})
+		Convey("Attacked() should only consider the attacking side's pieces", func() {
+			So(testBoard.SetPieces("k7/8/8/8/8/8/8/R1K5"), ShouldBeNil)
+			So(testBoard.Attacked(board.A8, board.Black), ShouldBeFalse)
+			So(testBoard.Attacked(board.A1, board.Black), ShouldBeFalse)
+			So(testBoard.Attacked(board.B8, board.Black), ShouldBeTrue)
+		})
+	})
+}
+
+func TestAttackersTo(t *testing.T) {
+	Convey("Given a board", t, func() {
+		testBoard := board.NewBoard()
+		So(testBoard.SetPieces("3qk3/8/1b6/4n3/R2N3r/8/4P3/4K3"), ShouldBeNil)
+		Convey("AttackersTo() should return every piece of the side attacking the square", func() {
+			So(testBoard.AttackersTo(board.D4, board.Black), ShouldResemble,
+				*board.NewBitboard(board.D8, board.H4, board.B6))
+			So(testBoard.AttackersTo(board.F3, board.Black), ShouldResemble,
+				*board.NewBitboard(board.E5))
+			So(testBoard.AttackersTo(board.F3, board.White), ShouldResemble,
+				*board.NewBitboard(board.E2, board.D4))
+			So(testBoard.AttackersTo(board.A5, board.Black), ShouldResemble, *board.NewBitboard(board.B6))
+		})
+		Convey("AttackersTo() should not see through blocking pieces", func() {
+			So(testBoard.AttackersTo(board.B4, board.Black), ShouldResemble, *board.NewBitboard())
+			So(testBoard.AttackersTo(board.E1, board.Black), ShouldResemble, *board.NewBitboard())
+		})
+		Convey("AttackMap() should return every square the side attacks", func() {
+			So(testBoard.SetPieces("7k/8/8/8/8/8/1P6/N3K3"), ShouldBeNil)
+			So(testBoard.AttackMap(board.White), ShouldResemble, *board.NewBitboard(
+				board.A3, board.C3,
+				board.B3, board.C2,
+				board.D1, board.F1, board.D2, board.E2, board.F2,
+			))
+			So(testBoard.AttackMap(board.Black), ShouldResemble, *board.NewBitboard(
+				board.G8, board.G7, board.H7,
+			))
+		})
+		Convey("AttackMap() should include squares behind a slider's first blocker only for that blocker", func() {
+			So(testBoard.SetPieces("7k/8/8/8/8/8/8/R1P1K3"), ShouldBeNil)
+			attacks := testBoard.AttackMap(board.White)
+			So(attacks.Occupied(board.C1), ShouldBeTrue)
+			So(attacks.Occupied(board.A8), ShouldBeTrue)
+			So(attacks.Occupied(board.D1), ShouldBeTrue)
+			So(attacks.Occupied(board.B2), ShouldBeTrue)
+			So(attacks.Occupied(board.A1), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package board
+
+//nolint:gochecknoglobals // this is a pseudo const
+var knightDirections = []direction{
+	{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2},
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var pawnCaptureDirections = map[Side][]direction{
+	White: {{-1, 1}, {1, 1}},
+	Black: {{-1, -1}, {1, -1}},
+}
+
+// leaperAttacks holds, for each square, the squares attacked by a knight,
+// king or pawn of each colour standing on it.
+type leaperAttacks struct {
+	knight [64]uint64
+	king   [64]uint64
+	pawn   [2][64]uint64
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var leapers = newLeaperAttacks()
+
+func stepMask(sqr Square, dirs []direction) uint64 {
+	var mask uint64
+
+	for _, dir := range dirs {
+		mask |= uint64(step(sqr, dir))
+	}
+
+	return mask
+}
+
+func newLeaperAttacks() *leaperAttacks {
+	tables := &leaperAttacks{}
+	kingDirections := append(append([]direction{}, orthagonalDirections...), diagonalDirections...)
+
+	for idx := range tables.knight {
+		sqr := SquareAt(idx)
+		tables.knight[idx] = stepMask(sqr, knightDirections)
+		tables.king[idx] = stepMask(sqr, kingDirections)
+		tables.pawn[White][idx] = stepMask(sqr, pawnCaptureDirections[White])
+		tables.pawn[Black][idx] = stepMask(sqr, pawnCaptureDirections[Black])
+	}
+
+	return tables
+}
+
+// KnightAttacks returns the squares a knight on sqr attacks.
+func KnightAttacks(sqr Square) BitBoard {
+	return BitBoard{Board: leapers.knight[sqr.Index()]}
+}
+
+// KingAttacks returns the squares a king on sqr attacks.
+func KingAttacks(sqr Square) BitBoard {
+	return BitBoard{Board: leapers.king[sqr.Index()]}
+}
+
+// PawnAttacks returns the squares a pawn of the given side on sqr attacks.
+func PawnAttacks(side Side, sqr Square) BitBoard {
+	return BitBoard{Board: leapers.pawn[side][sqr.Index()]}
+}
@@ -0,0 +1,50 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLeaperAttacks(t *testing.T) {
+	Convey("Given the leaper attack tables", t, func() {
+		Convey("KnightAttacks() should match KnightMoves() on every square", func() {
+			for _, sqr := range board.AllSquares {
+				attacks := board.KnightAttacks(sqr)
+				So(attacks.Squares(), ShouldHaveLength, len(board.KnightMoves(sqr)))
+				for _, dst := range board.KnightMoves(sqr) {
+					So(attacks.Occupied(dst), ShouldBeTrue)
+				}
+			}
+		})
+		Convey("KingAttacks() should match KingMoves() on every square", func() {
+			for _, sqr := range board.AllSquares {
+				attacks := board.KingAttacks(sqr)
+				So(attacks.Squares(), ShouldHaveLength, len(board.KingMoves(sqr)))
+				for _, dst := range board.KingMoves(sqr) {
+					So(attacks.Occupied(dst), ShouldBeTrue)
+				}
+			}
+		})
+		Convey("PawnAttacks() should return the diagonal squares in front of the pawn", func() {
+			testCases := []struct {
+				side     board.Side
+				sqr      board.Square
+				expected board.BitBoard
+			}{
+				{board.White, board.E4, *board.NewBitboard(board.D5, board.F5)},
+				{board.White, board.A2, *board.NewBitboard(board.B3)},
+				{board.White, board.H7, *board.NewBitboard(board.G8)},
+				{board.White, board.C8, *board.NewBitboard()},
+				{board.Black, board.E5, *board.NewBitboard(board.D4, board.F4)},
+				{board.Black, board.H7, *board.NewBitboard(board.G6)},
+				{board.Black, board.A2, *board.NewBitboard(board.B1)},
+				{board.Black, board.F1, *board.NewBitboard()},
+			}
+			for _, tc := range testCases {
+				So(board.PawnAttacks(tc.side, tc.sqr), ShouldResemble, tc.expected)
+			}
+		})
+	})
+}
@@ -36,20 +36,20 @@ func (q *Bishops) ValidMove(src, dst Square) bool {
 		Str("destination", dst.String()).
 		Msg("Validating Bishop move")
 
-	for _, validDst := range DiagonalMoves(src) {
-		if dst == validDst {
-			log.Debug().
-				Str("source", src.String()).
-				Str("destination", dst.String()).
-				Msg("Dest is a valid diagonal Move")
-
-			return true
-		}
-	}
+	// on an empty board a slider attacks every square it can move to.
+	attacks := BishopAttacks(src, BitBoard{})
 
-	return false
+	return attacks.Occupied(dst)
 }
 
 func (q *Bishops) Positions() *BitBoard {
 	return q.BitBoard
 }
+
+func (q *Bishops) Type() PieceType {
+	return BishopType
+}
+
+func (q *Bishops) Side() Side {
+	return q.Colour
+}
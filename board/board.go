@@ -1,10 +1,9 @@
 package board
 
 import (
-	"regexp"
+	"math/bits"
 	"strconv"
 	"strings"
-	"unicode"
 
 	"github.com/peteches/ChessEngine/errors"
 	"github.com/rs/zerolog/log"
@@ -30,17 +29,24 @@ func (bb *BitBoard) FlipBit(bit Square) {
 }
 
 func (bb *BitBoard) Squares() []Square {
-	squares := []Square{}
+	squares := make([]Square, 0, bits.OnesCount64(bb.Board))
 
-	for _, square := range AllSquares {
-		if (bb.Board & uint64(square)) > 0 {
-			squares = append(squares, square)
-		}
+	for remaining := *bb; remaining.Board != 0; {
+		squares = append(squares, remaining.PopSquare())
 	}
 
 	return squares
 }
 
+// PopSquare removes the lowest occupied square from the bitboard and returns
+// it, or returns 0 if the bitboard is empty.
+func (bb *BitBoard) PopSquare() Square {
+	sqr := Square(bb.Board & -bb.Board)
+	bb.Board &^= uint64(sqr)
+
+	return sqr
+}
+
 func (bb *BitBoard) Occupied(sqr Square) bool {
 	return (bb.Board & uint64(sqr)) > 0
 }
@@ -70,6 +76,11 @@ type Board struct {
 
 	WhitePawns *Pawns
 	BlackPawns *Pawns
+
+	// mailbox holds the piece on each square, indexed by Square.Index, so
+	// the piece on a square can be found without searching the bitboards.
+	// It must be kept in step with the bitboards, see Flip.
+	mailbox [64]ColouredPiece
 }
 
 func NewBoard() *Board {
@@ -89,42 +100,166 @@ func NewBoard() *Board {
 	}
 }
 
-// nolint: cyclop // can't be simplified any further
+// PieceAt returns the piece on sqr, or NoPiece if sqr is empty.
+func (b *Board) PieceAt(sqr Square) ColouredPiece {
+	return b.mailbox[sqr.Index()]
+}
+
+// PieceOn returns the set of pieces that has a piece on sqr, or nil if sqr is
+// empty.
+func (b *Board) PieceOn(sqr Square) Piece {
+	piece := b.PieceAt(sqr)
+	if piece == NoPiece {
+		return nil
+	}
+
+	return b.PieceOfType(piece.Colour(), piece.Type())
+}
+
+// Flip adds piece to sqr if it is not there, or removes it if it is, keeping
+// the mailbox in step with the bitboards.
+func (b *Board) Flip(piece Piece, sqr Square) {
+	piece.Positions().FlipBit(sqr)
+
+	if piece.Positions().Occupied(sqr) {
+		b.mailbox[sqr.Index()] = NewColouredPiece(piece.Side(), piece.Type())
+	} else {
+		b.mailbox[sqr.Index()] = NoPiece
+	}
+}
+
+// SyncMailbox rebuilds the mailbox from the bitboards. It is only needed when
+// a Board has been put together from its piece sets directly.
+func (b *Board) SyncMailbox() {
+	b.mailbox = [64]ColouredPiece{}
+
+	for _, side := range []Side{White, Black} {
+		for _, piece := range b.Pieces(side) {
+			for _, sqr := range piece.Positions().Squares() {
+				b.mailbox[sqr.Index()] = NewColouredPiece(side, piece.Type())
+			}
+		}
+	}
+}
+
 func (b *Board) OccupiedBy(sqr Square) string {
+	return b.PieceAt(sqr).String()
+}
+
+// Pieces returns every set of pieces belonging to side.
+func (b *Board) Pieces(side Side) []Piece {
+	switch side {
+	case White:
+		return []Piece{
+			b.WhiteKing, b.WhiteQueens, b.WhiteBishops,
+			b.WhiteKnights, b.WhiteRooks, b.WhitePawns,
+		}
+	case Black:
+		return []Piece{
+			b.BlackKing, b.BlackQueens, b.BlackBishops,
+			b.BlackKnights, b.BlackRooks, b.BlackPawns,
+		}
+	default:
+		return []Piece{}
+	}
+}
+
+// PieceOfType returns side's set of pieces of the given type, or nil for
+// NoPieceType.
+//
+//nolint:cyclop // one case per piece type and side
+func (b *Board) PieceOfType(side Side, pieceType PieceType) Piece {
+	white := side == White
+
 	switch {
-	case b.WhiteKing.BitBoard.Occupied(sqr):
-		return b.WhiteKing.String()
-	case b.WhiteQueens.Positions().Occupied(sqr):
-		return b.WhiteQueens.String()
-	case b.WhiteBishops.BitBoard.Occupied(sqr):
-		return b.WhiteBishops.String()
-	case b.WhiteKnights.BitBoard.Occupied(sqr):
-		return b.WhiteKnights.String()
-	case b.WhiteRooks.BitBoard.Occupied(sqr):
-		return b.WhiteRooks.String()
-	case b.WhitePawns.BitBoard.Occupied(sqr):
-		return b.WhitePawns.String()
-	case b.BlackKing.BitBoard.Occupied(sqr):
-		return b.BlackKing.String()
-	case b.BlackQueens.Positions().Occupied(sqr):
-		return b.BlackQueens.String()
-	case b.BlackBishops.BitBoard.Occupied(sqr):
-		return b.BlackBishops.String()
-	case b.BlackKnights.BitBoard.Occupied(sqr):
-		return b.BlackKnights.String()
-	case b.BlackRooks.BitBoard.Occupied(sqr):
-		return b.BlackRooks.String()
-	case b.BlackPawns.BitBoard.Occupied(sqr):
-		return b.BlackPawns.String()
+	case pieceType == KingType && white:
+		return b.WhiteKing
+	case pieceType == KingType:
+		return b.BlackKing
+	case pieceType == QueenType && white:
+		return b.WhiteQueens
+	case pieceType == QueenType:
+		return b.BlackQueens
+	case pieceType == RookType && white:
+		return b.WhiteRooks
+	case pieceType == RookType:
+		return b.BlackRooks
+	case pieceType == BishopType && white:
+		return b.WhiteBishops
+	case pieceType == BishopType:
+		return b.BlackBishops
+	case pieceType == KnightType && white:
+		return b.WhiteKnights
+	case pieceType == KnightType:
+		return b.BlackKnights
+	case pieceType == PawnType && white:
+		return b.WhitePawns
+	case pieceType == PawnType:
+		return b.BlackPawns
 	default:
-		return ""
+		return nil
 	}
 }
 
-func (b *Board) Occupied(sqr Square) bool {
-	occupiedBy := b.OccupiedBy(sqr)
+// OccupiedBySide returns true if sqr holds one of side's pieces.
+func (b *Board) OccupiedBySide(sqr Square, side Side) bool {
+	piece := b.PieceAt(sqr)
+
+	return piece != NoPiece && piece.Colour() == side
+}
+
+// Occupancy returns a bitboard of every occupied square.
+func (b *Board) Occupancy() BitBoard {
+	return BitBoard{Board: b.ColourOccupancy(White).Board | b.ColourOccupancy(Black).Board}
+}
+
+// ColourOccupancy returns a bitboard of the squares holding side's pieces.
+func (b *Board) ColourOccupancy(side Side) BitBoard {
+	var occupied BitBoard
+
+	for _, piece := range b.Pieces(side) {
+		occupied.Board |= piece.Positions().Board
+	}
+
+	return occupied
+}
+
+// TypeOccupancy returns a bitboard of the squares holding pieces of the given
+// type of either colour.
+func (b *Board) TypeOccupancy(pieceType PieceType) BitBoard {
+	var occupied BitBoard
+
+	for _, side := range []Side{White, Black} {
+		if piece := b.PieceOfType(side, pieceType); piece != nil {
+			occupied.Board |= piece.Positions().Board
+		}
+	}
 
-	return len(occupiedBy) > 0
+	return occupied
+}
+
+// Clone returns a deep copy of the board which can be altered without
+// affecting the original.
+func (b *Board) Clone() *Board {
+	return &Board{
+		WhiteKing:    &King{BitBoard: &BitBoard{Board: b.WhiteKing.BitBoard.Board}, Colour: White},
+		BlackKing:    &King{BitBoard: &BitBoard{Board: b.BlackKing.BitBoard.Board}, Colour: Black},
+		WhiteQueens:  &Queens{BitBoard: &BitBoard{Board: b.WhiteQueens.BitBoard.Board}, Colour: White},
+		BlackQueens:  &Queens{BitBoard: &BitBoard{Board: b.BlackQueens.BitBoard.Board}, Colour: Black},
+		WhiteBishops: &Bishops{BitBoard: &BitBoard{Board: b.WhiteBishops.BitBoard.Board}, Colour: White},
+		BlackBishops: &Bishops{BitBoard: &BitBoard{Board: b.BlackBishops.BitBoard.Board}, Colour: Black},
+		WhiteKnights: &Knights{BitBoard: &BitBoard{Board: b.WhiteKnights.BitBoard.Board}, Colour: White},
+		BlackKnights: &Knights{BitBoard: &BitBoard{Board: b.BlackKnights.BitBoard.Board}, Colour: Black},
+		WhiteRooks:   &Rooks{BitBoard: &BitBoard{Board: b.WhiteRooks.BitBoard.Board}, Colour: White},
+		BlackRooks:   &Rooks{BitBoard: &BitBoard{Board: b.BlackRooks.BitBoard.Board}, Colour: Black},
+		WhitePawns:   &Pawns{BitBoard: &BitBoard{Board: b.WhitePawns.BitBoard.Board}, Colour: White},
+		BlackPawns:   &Pawns{BitBoard: &BitBoard{Board: b.BlackPawns.BitBoard.Board}, Colour: Black},
+		mailbox:      b.mailbox,
+	}
+}
+
+func (b *Board) Occupied(sqr Square) bool {
+	return b.PieceAt(sqr) != NoPiece
 }
 
 func (b *Board) String() string {
@@ -160,6 +295,8 @@ func (b *Board) String() string {
 
 // nolint:funlen,cyclop // TODO look are refactoring this
 func (b *Board) SetPieces(pieces string) *errors.PiecePositionError {
+	defer b.SyncMailbox()
+
 	// reset all positions
 	b.BlackRooks.BitBoard.Board &= uint64(0)
 	b.BlackKnights.BitBoard.Board &= uint64(0)
@@ -257,199 +394,6 @@ func (b *Board) SetPieces(pieces string) *errors.PiecePositionError {
 	return nil
 }
 
-type parsedMove struct {
-	matches   []string
-	piece     string
-	src       Square
-	dst       Square
-	capture   bool
-	promotion string
-}
-
-func parseMove(lanMove string) *parsedMove {
-	r := "(?i)^(?P<piece>[NBRQK])?(?P<src>[A-H][1-8])(?P<capture>[-X])?(?P<dst>[A-H][1-8])(?P<promotionTo>[NBRQ])?$"
-	moveRegex := regexp.MustCompile(r)
-	matches := moveRegex.FindStringSubmatch(lanMove)
-
-	pieceIndex := moveRegex.SubexpIndex("piece")
-	srcIndex := moveRegex.SubexpIndex("src")
-	capIndex := moveRegex.SubexpIndex("capture")
-	dstIndex := moveRegex.SubexpIndex("dst")
-	promoIndex := moveRegex.SubexpIndex("promotionTo")
-
-	log.Debug().
-		Int("pieceIndex", pieceIndex).
-		Int("srcIndex", srcIndex).
-		Int("capIndex", capIndex).
-		Int("dstIndex", dstIndex).
-		Int("promoIndex", promoIndex).
-		Str("piece", matches[pieceIndex]).
-		Str("src", matches[srcIndex]).
-		Str("cap", matches[capIndex]).
-		Str("dst", matches[dstIndex]).
-		Str("promo", matches[promoIndex]).
-		Interface("RegexMatches", matches).
-		Msg("regex indexes")
-
-	return &parsedMove{
-		matches:   matches,
-		piece:     matches[pieceIndex],
-		src:       BoardMatrixStoI[strings.ToUpper(matches[srcIndex])],
-		dst:       BoardMatrixStoI[strings.ToUpper(matches[dstIndex])],
-		capture:   matches[capIndex] != "",
-		promotion: matches[promoIndex],
-	}
-}
-
-func (b *Board) clearPath(src, dst Square) bool {
-	log.Debug().
-		Str("src", src.String()).
-		Str("dst", dst.String()).
-		Msg("Checking if there are any pieces between src and dst")
-
-	inbetweenSquares := SquaresBetween(src, dst)
-	if len(inbetweenSquares) == 0 && !SquaresAdjacent(src, dst) {
-		return false
-	}
-
-	for _, inbetweenSqr := range inbetweenSquares {
-		if b.Occupied(inbetweenSqr) {
-			return false
-		}
-	}
-
-	return true
-}
-
-func (b *Board) rookCheck(checkingSide Side, kingSqr Square) bool {
-	var rooks *Rooks
-
-	switch checkingSide {
-	case Black:
-		rooks = b.BlackRooks
-	case White:
-		rooks = b.WhiteRooks
-	}
-
-	for _, rookSqr := range rooks.BitBoard.Squares() {
-		log.Debug().Msgf("Checking if rook on %s is checking king", rookSqr.String())
-
-		if b.clearPath(kingSqr, rookSqr) {
-			log.Debug().
-				Str("Fen", b.String()).
-				Str("CheckingPiece", rooks.String()).
-				Str("CheckingSquare", rookSqr.String()).
-				Msgf("Yes it can")
-
-			return true
-		}
-	}
-
-	return false
-}
-
-func (b *Board) knightCheck(checkingSide Side, kingSqr Square) bool {
-	var knights *Knights
-
-	switch checkingSide {
-	case Black:
-		knights = b.BlackKnights
-	case White:
-		knights = b.WhiteKnights
-	}
-
-	for _, knightMove := range KnightMoves(kingSqr) {
-		for _, knightSquare := range knights.BitBoard.Squares() {
-			if knightMove == knightSquare {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func (b *Board) bishopCheck(checkingSide Side, kingSqr Square) bool {
-	var bishops *Bishops
-
-	switch checkingSide {
-	case Black:
-		bishops = b.BlackBishops
-	case White:
-		bishops = b.WhiteBishops
-	}
-
-	for _, bishopSqr := range bishops.BitBoard.Squares() {
-		log.Debug().Msgf("Checking if bishop on %s is checking king", bishopSqr.String())
-
-		if b.clearPath(kingSqr, bishopSqr) {
-			log.Debug().
-				Str("Fen", b.String()).
-				Str("CheckingPiece", bishops.String()).
-				Str("CheckingSquare", bishopSqr.String()).
-				Msgf("Yes it can")
-
-			return true
-		}
-	}
-
-	return false
-}
-
-func (b *Board) queenCheck(checkingSide Side, kingSqr Square) bool {
-	var queens *Queens
-
-	switch checkingSide {
-	case Black:
-		queens = b.BlackQueens
-	case White:
-		queens = b.WhiteQueens
-	}
-
-	for _, queenSqr := range queens.Positions().Squares() {
-		log.Debug().Msgf("Checking if queen on %s is checking king", queenSqr.String())
-
-		if b.clearPath(kingSqr, queenSqr) {
-			log.Debug().
-				Str("Fen", b.String()).
-				Str("CheckingPiece", queens.String()).
-				Str("CheckingSquare", queenSqr.String()).
-				Msgf("Yes it can")
-
-			return true
-		}
-	}
-
-	return false
-}
-
-func (b *Board) pawnCheck(checkingSide Side, kingSqr Square) bool {
-	var pawns *Pawns
-
-	var pawnCapFunc func(Square) []Square
-
-	switch checkingSide {
-	case Black:
-		pawns = b.BlackPawns
-		pawnCapFunc = BlackPawnCaptureMoves
-	case White:
-		pawns = b.WhitePawns
-		pawnCapFunc = WhitePawnCaptureMoves
-	}
-
-	for _, pawnSqr := range pawns.BitBoard.Squares() {
-		log.Debug().Msgf("Checking if pawn on %s is checking king", pawnSqr.String())
-
-		for _, captureSqr := range pawnCapFunc(pawnSqr) {
-			if captureSqr == kingSqr {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
 func (b *Board) IsInCheck(side Side) bool {
 	var checkedKing *King
 
@@ -464,142 +408,121 @@ func (b *Board) IsInCheck(side Side) bool {
 		checkingSide = Black
 	}
 
-	checkedKingSquare := checkedKing.BitBoard.Squares()[0] // only one king
-
-	switch {
-	case b.rookCheck(checkingSide, checkedKingSquare):
-		log.Debug().Msg("Rooks can see Enemy King")
-
-		return true
-	case b.knightCheck(checkingSide, checkedKingSquare):
-		log.Debug().Msg("Knights can see Enemy King")
-
-		return true
-	case b.bishopCheck(checkingSide, checkedKingSquare):
-		log.Debug().Msg("Bishops can see Enemy King")
-
-		return true
-	case b.queenCheck(checkingSide, checkedKingSquare):
-		log.Debug().Msg("Queens can see Enemy King")
-
-		return true
-	case b.pawnCheck(checkingSide, checkedKingSquare):
-		log.Debug().Msg("Pawns can see Enemy King")
-
-		return true
-	default:
-		log.Debug().Msg("No Pieces can see enemy king.")
+	for _, kingSqr := range checkedKing.BitBoard.Squares() {
+		if b.AttackersTo(kingSqr, checkingSide).Board != 0 {
+			log.Debug().
+				Str("Fen", b.String()).
+				Str("KingSquare", kingSqr.String()).
+				Msg("King is in check")
 
-		return false
+			return true
+		}
 	}
-}
 
-// nolint:funlen,cyclop // can't make this any smaller
-func (b *Board) MakeMove(side Side, lanMove string) (*Board, *errors.MoveError) {
-	parsedMove := parseMove(lanMove)
+	return false
+}
 
-	var movingPiece Piece
+// MakeMove checks move is valid for side and if so returns a copy of the
+// board with the move played. The board itself is left unchanged.
+func (b *Board) MakeMove(side Side, move Move) (*Board, *errors.MoveError) {
+	newBoard := b.Clone()
+	src, dst := move.From(), move.To()
 
-	newBoard := NewBoard()
-	// ignore errors we know this position is legal
-	_ = newBoard.SetPieces(b.String())
+	movingPiece := newBoard.PieceOn(src)
 
-	switch strings.ToUpper(parsedMove.piece) {
-	case "", "P":
-		switch side {
-		case Black:
-			movingPiece = newBoard.BlackPawns
-		case White:
-			movingPiece = newBoard.WhitePawns
-		}
-	case "R":
-		switch side {
-		case Black:
-			movingPiece = newBoard.BlackRooks
-		case White:
-			movingPiece = newBoard.WhiteRooks
-		}
-	case "N":
-		switch side {
-		case Black:
-			movingPiece = newBoard.BlackKnights
-		case White:
-			movingPiece = newBoard.WhiteKnights
-		}
-	case "B":
-		switch side {
-		case Black:
-			movingPiece = newBoard.BlackBishops
-		case White:
-			movingPiece = newBoard.WhiteBishops
-		}
-	case "Q":
-		switch side {
-		case Black:
-			movingPiece = newBoard.BlackQueens
-		case White:
-			movingPiece = newBoard.WhiteQueens
-		}
-	case "K":
-		switch side {
-		case Black:
-			movingPiece = newBoard.BlackKing
-		case White:
-			movingPiece = newBoard.WhiteKing
+	if movingPiece == nil || !newBoard.OccupiedBySide(src, side) {
+		return nil, &errors.MoveError{
+			Fen:  b.String(),
+			Err:  "Illegal move, the src square does not contain the expected piece.",
+			Move: move.String(),
 		}
 	}
 
-	if !movingPiece.ValidMove(parsedMove.src, parsedMove.dst) {
+	if !movingPiece.ValidMove(src, dst) {
 		log.Debug().
-			Interface("move", parsedMove).
+			Str("move", move.String()).
 			Msg("Move is invalid")
 
 		return nil, &errors.MoveError{
 			Fen:  b.String(),
 			Err:  "Invalid move.",
-			Move: lanMove,
+			Move: move.String(),
 		}
 	}
 
-	if newBoard.OccupiedBy(parsedMove.src) != movingPiece.String() {
+	if newBoard.OccupiedBySide(dst, side) {
 		return nil, &errors.MoveError{
 			Fen:  b.String(),
-			Err:  "Illegal move, the src square does not contain the expected piece.",
-			Move: lanMove,
-		}
-	}
-
-	if newBoard.Occupied(parsedMove.dst) {
-		if unicode.IsUpper(rune(newBoard.OccupiedBy(parsedMove.src)[0])) && unicode.IsUpper(rune(newBoard.OccupiedBy(parsedMove.dst)[0])) {
-			return nil, &errors.MoveError{
-				Fen:  b.String(),
-				Err:  "Illegal move, you cannot capture your own pieces.",
-				Move: lanMove,
-			}
+			Err:  "Illegal move, you cannot capture your own pieces.",
+			Move: move.String(),
 		}
 	}
 
-	for _, sqr := range SquaresBetween(parsedMove.src, parsedMove.dst) {
+	for _, sqr := range SquaresBetween(src, dst) {
 		if newBoard.Occupied(sqr) {
 			return nil, &errors.MoveError{
 				Fen:  b.String(),
 				Err:  "Illegal move, there is an intervening piece.",
-				Move: lanMove,
+				Move: move.String(),
 			}
 		}
 	}
 
+	if err := b.CheckPromotion(move); err != nil {
+		return nil, err
+	}
+
 	// actually move the piece
-	movingPiece.Positions().FlipBit(parsedMove.src)
-	movingPiece.Positions().FlipBit(parsedMove.dst)
+	if captured := newBoard.PieceOn(dst); captured != nil {
+		newBoard.Flip(captured, dst)
+	}
+
+	newBoard.Flip(movingPiece, src)
+
+	if move.Promotion() == NoPieceType {
+		newBoard.Flip(movingPiece, dst)
+	} else {
+		newBoard.Flip(newBoard.PieceOfType(side, move.Promotion()), dst)
+	}
 
 	if newBoard.IsInCheck(side) {
 		return nil, &errors.MoveError{
 			Fen:  b.String(),
 			Err:  "Illegal move, you cannot end your turn in check.",
-			Move: lanMove,
+			Move: move.String(),
 		}
 	}
 
-	return nil, nil
+	return newBoard, nil
+}
+
+// CheckPromotion returns an error if move's promotion piece does not suit the
+// move: a pawn reaching the last rank must promote to a queen, rook, bishop or
+// knight and no other move may promote.
+func (b *Board) CheckPromotion(move Move) *errors.MoveError {
+	_, isPawn := b.PieceOn(move.From()).(*Pawns)
+	dst := move.To()
+	lastRank := dst.Rank() == FirstRank || dst.Rank() == EighthRank
+	promotion := move.Promotion()
+
+	var reason string
+
+	switch {
+	case isPawn && lastRank && promotion == NoPieceType:
+		reason = "Illegal move, a pawn reaching the last rank must be promoted."
+	case promotion == NoPieceType:
+		return nil
+	case !isPawn || !lastRank:
+		reason = "Illegal move, only a pawn reaching the last rank can be promoted."
+	case promotion == PawnType || promotion == KingType:
+		reason = "Illegal move, pawns can only be promoted to a queen, rook, bishop or knight."
+	default:
+		return nil
+	}
+
+	return &errors.MoveError{
+		Fen:  b.String(),
+		Err:  reason,
+		Move: move.String(),
+	}
 }
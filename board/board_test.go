@@ -218,6 +218,15 @@ func TestBitboard(t *testing.T) {
 			So(bitboard.Squares(), ShouldContain, board.H3)
 		})
 
+		Convey("When PopSquare() method called it removes and returns the lowest square", func() {
+			bitboard.FlipBit(board.H3)
+			bitboard.FlipBit(board.B1)
+			So(bitboard.PopSquare(), ShouldEqual, board.B1)
+			So(bitboard.PopSquare(), ShouldEqual, board.H3)
+			So(bitboard.Board, ShouldEqual, 0)
+			So(bitboard.PopSquare(), ShouldEqual, 0)
+		})
+
 		Convey("When Occupied() method called with square, returns true if square occupied", func() {
 			for _, sqr := range board.AllSquares {
 				So(bitboard.Occupied(sqr), ShouldEqual, false)
@@ -293,6 +302,7 @@ func TestBoard(t *testing.T) {
 			for piecePositions, expectedPiecePosition := range validPiecePositions {
 				err := testBoard.SetPieces(piecePositions)
 				So(err, ShouldEqual, nil)
+				expectedPiecePosition.SyncMailbox()
 				So(*testBoard, ShouldResemble, expectedPiecePosition)
 			}
 		})
@@ -316,6 +326,9 @@ func TestBoard(t *testing.T) {
 			Convey("return false if Side is not in check", func() {
 				notInCheck := []string{
 					"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+					"4k3/3R4/8/8/8/8/8/4K3",
+					"4k3/8/3B4/8/8/8/8/4K3",
+					"4k3/4p3/8/8/4Q3/8/8/4K3",
 				}
 				for _, tc := range notInCheck {
 					err := testBoard.SetPieces(tc)
@@ -327,71 +340,71 @@ func TestBoard(t *testing.T) {
 		Convey("The MakeMove() method should", func() {
 			Convey("return an error if given an invalid move", func() {
 				testCases := []struct {
-					move string
+					move board.Move
 					side board.Side
 					err  *errors.MoveError
 				}{
 					{
-						"e2-e5",
-						board.Black,
+						board.NewMove(board.E2, board.E5, board.NoPieceType),
+						board.White,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "e2-e5",
+							Move: "e2e5",
 						},
 					},
 					{
-						"e2-e5",
-						board.White,
+						board.NewMove(board.E7, board.E4, board.NoPieceType),
+						board.Black,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "e2-e5",
+							Move: "e7e4",
 						},
 					},
 					{
-						"qe2-h6",
+						board.NewMove(board.D8, board.H3, board.NoPieceType),
 						board.Black,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "qe2-h6",
+							Move: "d8h3",
 						},
 					},
 					{
-						"re2-b5",
+						board.NewMove(board.H8, board.G6, board.NoPieceType),
 						board.Black,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "re2-b5",
+							Move: "h8g6",
 						},
 					},
 					{
-						"be2-e5",
+						board.NewMove(board.C8, board.C6, board.NoPieceType),
 						board.Black,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "be2-e5",
+							Move: "c8c6",
 						},
 					},
 					{
-						"ne2-e5",
+						board.NewMove(board.G8, board.G6, board.NoPieceType),
 						board.Black,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "ne2-e5",
+							Move: "g8g6",
 						},
 					},
 					{
-						"ke2-e5",
+						board.NewMove(board.E8, board.E6, board.NoPieceType),
 						board.Black,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Invalid move.",
-							Move: "ke2-e5",
+							Move: "e8e6",
 						},
 					},
 				}
@@ -403,48 +416,68 @@ func TestBoard(t *testing.T) {
 			Convey("return a MoveError if the move is Valid but Illegal", func() {
 				testCases := []struct {
 					position string
-					move     string
+					move     board.Move
 					side     board.Side
 					err      *errors.MoveError
 				}{
 					{
 						"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
-						"bC1-A3",
+						board.NewMove(board.C1, board.A3, board.NoPieceType),
 						board.White,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Illegal move, there is an intervening piece.",
-							Move: "bC1-A3",
+							Move: "c1a3",
+						},
+					},
+					{
+						"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+						board.NewMove(board.C8, board.A6, board.NoPieceType),
+						board.White,
+						&errors.MoveError{
+							Fen:  testBoard.String(),
+							Err:  "Illegal move, the src square does not contain the expected piece.",
+							Move: "c8a6",
 						},
 					},
 					{
 						"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
-						"qC1-A3",
+						board.NewMove(board.E4, board.E5, board.NoPieceType),
 						board.White,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Illegal move, the src square does not contain the expected piece.",
-							Move: "qC1-A3",
+							Move: "e4e5",
 						},
 					},
 					{
 						"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
-						"kE1-D1",
+						board.NewMove(board.E1, board.D1, board.NoPieceType),
 						board.White,
 						&errors.MoveError{
 							Fen:  testBoard.String(),
 							Err:  "Illegal move, you cannot capture your own pieces.",
-							Move: "kE1-D1",
+							Move: "e1d1",
+						},
+					},
+					{
+						"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+						board.NewMove(board.E8, board.D8, board.NoPieceType),
+						board.Black,
+						&errors.MoveError{
+							Fen:  testBoard.String(),
+							Err:  "Illegal move, you cannot capture your own pieces.",
+							Move: "e8d8",
 						},
 					},
 					{
 						"rnbqkbnr/ppppRppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
-						"nG8-H6",
+						board.NewMove(board.G8, board.H6, board.NoPieceType),
 						board.Black,
 						&errors.MoveError{
 							Fen:  "rnbqkbnr/ppppRppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
 							Err:  "Illegal move, you cannot end your turn in check.",
-							Move: "nG8-H6",
+							Move: "g8h6",
 						},
 					},
 				}
@@ -456,18 +489,114 @@ func TestBoard(t *testing.T) {
 				}
 			})
 
-			SkipConvey("update the board with the relevant move", func() {
-				testCases := map[string]string{
-					"e2-e4": "rnbqkbnr/pppppppp/8/8/8/4P3/PPPP1PPP/RNBQKBNR",
+			Convey("return a copy of the board with the move played", func() {
+				testCases := map[board.Move]string{
+					board.NewMove(board.E2, board.E4, board.NoPieceType): "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR",
+					board.NewMove(board.G1, board.F3, board.NoPieceType): "rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKB1R",
 				}
 
 				for move, resultFen := range testCases {
+					So(testBoard.SetPieces("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR"), ShouldBeNil)
+					newBoard, err := testBoard.MakeMove(board.White, move)
 					So(err, ShouldBeNil)
+					So(newBoard.String(), ShouldEqual, resultFen)
+					So(testBoard.String(), ShouldEqual, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR")
+				}
+			})
+
+			Convey("promote a pawn reaching the last rank", func() {
+				So(testBoard.SetPieces("4k3/1P6/8/8/8/8/6p1/4K3"), ShouldBeNil)
+				newBoard, err := testBoard.MakeMove(board.White, board.NewMove(board.B7, board.B8, board.KnightType))
+				So(err, ShouldBeNil)
+				So(newBoard.String(), ShouldEqual, "1N2k3/8/8/8/8/8/6p1/4K3")
+				newBoard, err = testBoard.MakeMove(board.Black, board.NewMove(board.G2, board.G1, board.RookType))
+				So(err, ShouldBeNil)
+				So(newBoard.String(), ShouldEqual, "4k3/1P6/8/8/8/8/8/4K1r1")
+			})
+
+			Convey("return a MoveError if a promotion is missing or illegal", func() {
+				So(testBoard.SetPieces("4k3/1P6/8/8/8/8/8/4K3"), ShouldBeNil)
+				testCases := map[board.Move]string{
+					board.NewMove(board.B7, board.B8, board.NoPieceType): "Illegal move, a pawn reaching the last rank must be promoted.",
+					board.NewMove(board.E1, board.E2, board.QueenType):   "Illegal move, only a pawn reaching the last rank can be promoted.",
+					board.NewMove(board.B7, board.B8, board.PawnType):    "Illegal move, pawns can only be promoted to a queen, rook, bishop or knight.",
+				}
+				for move, reason := range testCases {
 					_, err := testBoard.MakeMove(board.White, move)
-					So(err, ShouldBeNil)
-					So(testBoard.String(), ShouldEqual, resultFen)
+					So(err, ShouldResemble, &errors.MoveError{
+						Fen:  "4k3/1P6/8/8/8/8/8/4K3",
+						Err:  reason,
+						Move: move.String(),
+					})
 				}
 			})
 		})
 	})
 }
+
+//nolint:funlen // convey testing is verbose
+func TestBoardQueries(t *testing.T) {
+	Convey("Given a Board with pieces set", t, func() {
+		testBoard := board.NewBoard()
+		So(testBoard.SetPieces("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR"), ShouldBeNil)
+		Convey("PieceOn() should return the set of pieces occupying the square", func() {
+			So(testBoard.PieceOn(board.E1), ShouldEqual, testBoard.WhiteKing)
+			So(testBoard.PieceOn(board.D8), ShouldEqual, testBoard.BlackQueens)
+			So(testBoard.PieceOn(board.A2), ShouldEqual, testBoard.WhitePawns)
+			So(testBoard.PieceOn(board.E4), ShouldBeNil)
+		})
+		Convey("PieceAt() should return the piece on the square", func() {
+			So(testBoard.PieceAt(board.E1), ShouldEqual, board.NewColouredPiece(board.White, board.KingType))
+			So(testBoard.PieceAt(board.G8), ShouldEqual, board.NewColouredPiece(board.Black, board.KnightType))
+			So(testBoard.PieceAt(board.E4), ShouldEqual, board.NoPiece)
+		})
+		Convey("Flip() should keep the mailbox in step with the bitboards", func() {
+			testBoard.Flip(testBoard.WhitePawns, board.E2)
+			testBoard.Flip(testBoard.WhitePawns, board.E4)
+			So(testBoard.PieceAt(board.E2), ShouldEqual, board.NoPiece)
+			So(testBoard.PieceAt(board.E4), ShouldEqual, board.NewColouredPiece(board.White, board.PawnType))
+			So(testBoard.WhitePawns.BitBoard.Occupied(board.E4), ShouldBeTrue)
+			synced := testBoard.Clone()
+			synced.SyncMailbox()
+			So(synced, ShouldResemble, testBoard)
+		})
+		Convey("ColourOccupancy() and TypeOccupancy() should return the matching squares", func() {
+			So(testBoard.ColourOccupancy(board.White), ShouldResemble, board.BitBoard{Board: 0xffff})
+			So(testBoard.ColourOccupancy(board.Black), ShouldResemble, board.BitBoard{Board: 0xffff << 48})
+			So(testBoard.TypeOccupancy(board.RookType), ShouldResemble,
+				*board.NewBitboard(board.A1, board.H1, board.A8, board.H8))
+			So(testBoard.TypeOccupancy(board.NoPieceType), ShouldResemble, board.BitBoard{})
+		})
+		Convey("Pieces() should return all the pieces of one side", func() {
+			So(testBoard.Pieces(board.White), ShouldHaveLength, 6)
+			So(testBoard.Pieces(board.White), ShouldContain, board.Piece(testBoard.WhiteRooks))
+			So(testBoard.Pieces(board.Black), ShouldContain, board.Piece(testBoard.BlackPawns))
+		})
+		Convey("OccupiedBySide() should return true only for that side's pieces", func() {
+			So(testBoard.OccupiedBySide(board.E2, board.White), ShouldBeTrue)
+			So(testBoard.OccupiedBySide(board.E2, board.Black), ShouldBeFalse)
+			So(testBoard.OccupiedBySide(board.E7, board.Black), ShouldBeTrue)
+			So(testBoard.OccupiedBySide(board.E4, board.Black), ShouldBeFalse)
+		})
+		Convey("PieceOfType() should return the side's set of that piece type", func() {
+			So(testBoard.PieceOfType(board.White, board.QueenType), ShouldEqual, testBoard.WhiteQueens)
+			So(testBoard.PieceOfType(board.Black, board.KnightType), ShouldEqual, testBoard.BlackKnights)
+			So(testBoard.PieceOfType(board.Black, board.PawnType), ShouldEqual, testBoard.BlackPawns)
+			So(testBoard.PieceOfType(board.White, board.NoPieceType), ShouldBeNil)
+		})
+		Convey("Occupancy() should return every occupied square", func() {
+			occupied := testBoard.Occupancy()
+			So(occupied.Squares(), ShouldHaveLength, 32)
+			So(occupied.Occupied(board.E1), ShouldBeTrue)
+			So(occupied.Occupied(board.H7), ShouldBeTrue)
+			So(occupied.Occupied(board.E4), ShouldBeFalse)
+		})
+		Convey("Clone() should return an independent copy", func() {
+			clone := testBoard.Clone()
+			So(clone, ShouldResemble, testBoard)
+			clone.WhitePawns.BitBoard.FlipBit(board.E2)
+			So(testBoard.WhitePawns.BitBoard.Occupied(board.E2), ShouldBeTrue)
+			So(clone.WhitePawns.BitBoard.Occupied(board.E2), ShouldBeFalse)
+		})
+	})
+}
@@ -53,3 +53,11 @@ func (q *King) ValidMove(src, dst Square) bool {
 func (q *King) Positions() *BitBoard {
 	return q.BitBoard
 }
+
+func (q *King) Type() PieceType {
+	return KingType
+}
+
+func (q *King) Side() Side {
+	return q.Colour
+}
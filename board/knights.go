@@ -53,3 +53,11 @@ func (q *Knights) ValidMove(src, dst Square) bool {
 func (q *Knights) Positions() *BitBoard {
 	return q.BitBoard
 }
+
+func (q *Knights) Type() PieceType {
+	return KnightType
+}
+
+func (q *Knights) Side() Side {
+	return q.Colour
+}
@@ -0,0 +1,235 @@
+package board
+
+import "math/bits"
+
+// magicSeed is fixed so the same magics are found on every run.
+const magicSeed uint64 = 0x5a1f3c6e9d2b7048
+
+// rookMagics and bishopMagics were found by findMagic from magicSeed. Storing
+// them saves searching for them each time the program starts.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var rookMagics = [64]uint64{
+	0x9880001020400080, 0x1140400010002000, 0x4980089000822000, 0x0100040821001000,
+	0x0200080420020010, 0x0080040002008001, 0x040010080400af02, 0x820005210a44008a,
+	0x0200800020804000, 0x0000804000200081, 0x0410808020001000, 0x2190800800100080,
+	0x2001808008002400, 0x8c10804400800200, 0x0009000100020044, 0x104200020c204085,
+	0x0040008000805820, 0x0150014040012002, 0x2110010100402000, 0x0031818008001000,
+	0x004a020008102005, 0x0002008004008002, 0x1020040088022130, 0x1016020004188061,
+	0x0000802180004004, 0x0860400080200080, 0x0110001080200080, 0x0000080080100084,
+	0xc418040080800800, 0x1020040080800200, 0x0017000100040200, 0x0010040200004081,
+	0x0080004000402000, 0x0810002000400044, 0x1000820022004010, 0x4000080082801000,
+	0x0000040080800800, 0x1001000803000400, 0x0010020001010004, 0x01028410820028c1,
+	0x1082209040098000, 0x0280804001010024, 0x4020002010008080, 0x00c8100009010020,
+	0x1800100801010004, 0x0082102040080104, 0x0601000200010004, 0x0880410040920004,
+	0x0c20210040800100, 0x0000502102008200, 0x1000802000100080, 0xc900080080100080,
+	0x0000080080040080, 0x880c800400020080, 0x0095210208101400, 0x0542049d00440200,
+	0x40030090a0c20082, 0x2206024011082082, 0x000108a0001100c1, 0x0000090020041001,
+	0x0202000408201002, 0x0281000208040001, 0x1402a2011008880c, 0x8414208401002042,
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var bishopMagics = [64]uint64{
+	0x0020080908098018, 0x0430048804404010, 0xb010240362420300, 0x0084404080004840,
+	0x00040420900800c8, 0x14b20144a0008908, 0x8000482208220010, 0x0010240202100224,
+	0x4020409002622040, 0x0404840108022480, 0x2008220210420002, 0x0140824081004200,
+	0x0000011040200420, 0x0048308220600000, 0x00008400828820a0, 0x0008002208020800,
+	0x020a001010011800, 0x803280a00a840706, 0x0810020114028011, 0x404c040804210820,
+	0x0204049084a00008, 0x0052000108094408, 0x00844d0216100418, 0x0006040022012402,
+	0x4020100004054815, 0x2810480010420080, 0x0102180211034400, 0x8020480008820040,
+	0x6491001009004004, 0x400a008044100080, 0x3008060100462211, 0x001211a0114c0e01,
+	0x4282082050400200, 0x0001080820023048, 0x4000109000081040, 0x0000040400080120,
+	0x0004040400401100, 0x00c2083200084044, 0x40323c0042010800, 0x3044011420020084,
+	0x105401484811c202, 0x4000880110088900, 0x2500514028021006, 0x0000104200800804,
+	0x0842020204100200, 0x0082200200802408, 0x01041000a90a2a04, 0x00c4110208281600,
+	0x1040808820110080, 0x0006010401048000, 0x4001028201410800, 0x0280022020881004,
+	0x0200010803040004, 0x0080409002008040, 0x1aa0226288010004, 0x0108100102002450,
+	0x0009002104204400, 0xc440090048420821, 0x0001140080480801, 0x0060200600411080,
+	0x0030020049610100, 0x0200002224010a00, 0x000020141c080054, 0x0ca0081000588220,
+}
+
+// magicEntry finds the attacks of a slider on one square with a perfect hash
+// of the occupancy of the squares that can block it. Multiplying the blockers
+// by the magic number gathers their bits into the top of the product, which is
+// shifted down to give an index into attacks.
+type magicEntry struct {
+	mask    uint64
+	magic   uint64
+	shift   uint8
+	attacks []uint64
+}
+
+func (m *magicEntry) index(occupied uint64) uint64 {
+	return ((occupied & m.mask) * m.magic) >> m.shift
+}
+
+// sliderAttacks holds the magic lookups for rooks and bishops on every square.
+type sliderAttacks struct {
+	rook   [64]magicEntry
+	bishop [64]magicEntry
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var sliders = newSliderAttacks(magicSeed, &rookMagics, &bishopMagics)
+
+// xorShift64Star is a small pseudo random number generator used to search for
+// magics.
+type xorShift64Star uint64
+
+//nolint:gomnd // constants of the xorshift64* algorithm
+func (x *xorShift64Star) next() uint64 {
+	*x ^= *x >> 12
+	*x ^= *x << 25
+	*x ^= *x >> 27
+
+	return uint64(*x) * 0x2545f4914f6cdd1d
+}
+
+// sparse returns a random number with few bits set, which make good magics.
+func (x *xorShift64Star) sparse() uint64 {
+	return x.next() & x.next() & x.next()
+}
+
+// slowSliderAttacks walks each ray from sqr until it leaves the board or hits
+// an occupied square, which is included.
+func slowSliderAttacks(sqr Square, occupied uint64, dirs []direction) uint64 {
+	var attacks uint64
+
+	for _, dir := range dirs {
+		for target := step(sqr, dir); target != 0; target = step(target, dir) {
+			attacks |= uint64(target)
+
+			if occupied&uint64(target) != 0 {
+				break
+			}
+		}
+	}
+
+	return attacks
+}
+
+// blockerMask returns the squares which can block a slider on sqr. The last
+// square of each ray never blocks anything beyond it so is left out.
+func blockerMask(sqr Square, dirs []direction) uint64 {
+	var mask uint64
+
+	for _, dir := range dirs {
+		for target := step(sqr, dir); target != 0 && step(target, dir) != 0; target = step(target, dir) {
+			mask |= uint64(target)
+		}
+	}
+
+	return mask
+}
+
+// magicCandidates holds every occupancy of a slider's blocker mask along with
+// the attacks for each.
+type magicCandidates struct {
+	occupancies []uint64
+	attacks     []uint64
+}
+
+func newMagicCandidates(sqr Square, mask uint64, dirs []direction) magicCandidates {
+	size := 1 << bits.OnesCount64(mask)
+	candidates := magicCandidates{
+		occupancies: make([]uint64, 0, size),
+		attacks:     make([]uint64, 0, size),
+	}
+
+	// enumerate every subset of the mask with the carry-rippler trick.
+	for subset := uint64(0); ; {
+		candidates.occupancies = append(candidates.occupancies, subset)
+		candidates.attacks = append(candidates.attacks, slowSliderAttacks(sqr, subset, dirs))
+
+		subset = (subset - mask) & mask
+		if subset == 0 {
+			return candidates
+		}
+	}
+}
+
+// fill stores the attacks for every occupancy in entry.attacks using the
+// entry's magic, returning false if two occupancies with different attacks
+// collide. epoch marks the slots filled by this attempt so the table does not
+// need clearing between attempts.
+func (m *magicEntry) fill(candidates magicCandidates, epoch []int, attempt int) bool {
+	for idx, occupied := range candidates.occupancies {
+		key := m.index(occupied)
+
+		if epoch[key] == attempt && m.attacks[key] != candidates.attacks[idx] {
+			return false
+		}
+
+		epoch[key] = attempt
+		m.attacks[key] = candidates.attacks[idx]
+	}
+
+	return true
+}
+
+// newMagicEntry builds the lookup for a slider on sqr using magic, searching
+// for a new magic if it does not work.
+func newMagicEntry(sqr Square, dirs []direction, magic uint64, rng *xorShift64Star) magicEntry {
+	mask := blockerMask(sqr, dirs)
+	relevantBits := bits.OnesCount64(mask)
+	candidates := newMagicCandidates(sqr, mask, dirs)
+	entry := magicEntry{
+		mask:    mask,
+		magic:   magic,
+		shift:   uint8(64 - relevantBits),
+		attacks: make([]uint64, 1<<relevantBits),
+	}
+	epoch := make([]int, len(entry.attacks))
+
+	if entry.fill(candidates, epoch, 1) {
+		return entry
+	}
+
+	for attempt := 2; ; attempt++ {
+		entry.magic = rng.sparse()
+
+		//nolint:gomnd // a magic needs enough bits in the top byte to spread the index
+		if bits.OnesCount64((mask*entry.magic)>>56) < 6 {
+			continue
+		}
+
+		if entry.fill(candidates, epoch, attempt) {
+			return entry
+		}
+	}
+}
+
+func newSliderAttacks(seed uint64, rookMagics, bishopMagics *[64]uint64) *sliderAttacks {
+	rng := xorShift64Star(seed)
+	tables := &sliderAttacks{}
+
+	for idx := range tables.rook {
+		sqr := SquareAt(idx)
+		tables.rook[idx] = newMagicEntry(sqr, orthagonalDirections, rookMagics[idx], &rng)
+		tables.bishop[idx] = newMagicEntry(sqr, diagonalDirections, bishopMagics[idx], &rng)
+	}
+
+	return tables
+}
+
+// RookAttacks returns the squares a rook on sqr attacks given the occupied
+// squares. Each ray stops at, and includes, the first occupied square.
+func RookAttacks(sqr Square, occupied BitBoard) BitBoard {
+	entry := &sliders.rook[sqr.Index()]
+
+	return BitBoard{Board: entry.attacks[entry.index(occupied.Board)]}
+}
+
+// BishopAttacks returns the squares a bishop on sqr attacks given the occupied
+// squares. Each ray stops at, and includes, the first occupied square.
+func BishopAttacks(sqr Square, occupied BitBoard) BitBoard {
+	entry := &sliders.bishop[sqr.Index()]
+
+	return BitBoard{Board: entry.attacks[entry.index(occupied.Board)]}
+}
+
+// QueenAttacks returns the squares a queen on sqr attacks given the occupied
+// squares.
+func QueenAttacks(sqr Square, occupied BitBoard) BitBoard {
+	return BitBoard{Board: RookAttacks(sqr, occupied).Board | BishopAttacks(sqr, occupied).Board}
+}
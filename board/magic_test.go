@@ -0,0 +1,58 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // convey testing is verbose
+func TestSliderAttacks(t *testing.T) {
+	Convey("Given the magic slider attack lookups", t, func() {
+		empty := board.BitBoard{}
+		Convey("On an empty board they should match the ray moves on every square", func() {
+			for _, sqr := range board.AllSquares {
+				rook := board.RookAttacks(sqr, empty)
+				bishop := board.BishopAttacks(sqr, empty)
+				queen := board.QueenAttacks(sqr, empty)
+				orthagonal := append(board.OrthaganolFileMoves(sqr), board.OrthaganolRankMoves(sqr)...)
+				So(rook.Squares(), ShouldHaveLength, len(orthagonal))
+				for _, dst := range orthagonal {
+					So(rook.Occupied(dst), ShouldBeTrue)
+				}
+				So(bishop.Squares(), ShouldHaveLength, len(board.DiagonalMoves(sqr)))
+				for _, dst := range board.DiagonalMoves(sqr) {
+					So(bishop.Occupied(dst), ShouldBeTrue)
+				}
+				So(queen.Board, ShouldEqual, rook.Board|bishop.Board)
+			}
+		})
+		Convey("They should stop at, and include, the first blocker on each ray", func() {
+			occupied := *board.NewBitboard(board.D6, board.B4, board.D2, board.G4, board.F6, board.B2)
+			rook := board.RookAttacks(board.D4, occupied)
+			So(rook, ShouldResemble, *board.NewBitboard(
+				board.D5, board.D6,
+				board.D3, board.D2,
+				board.C4, board.B4,
+				board.E4, board.F4, board.G4,
+			))
+			bishop := board.BishopAttacks(board.D4, occupied)
+			So(bishop, ShouldResemble, *board.NewBitboard(
+				board.E5, board.F6,
+				board.C5, board.B6, board.A7,
+				board.E3, board.F2, board.G1,
+				board.C3, board.B2,
+			))
+		})
+		Convey("Blockers beyond the first should make no difference", func() {
+			near := *board.NewBitboard(board.A3)
+			far := *board.NewBitboard(board.A3, board.A5, board.A7)
+			So(board.RookAttacks(board.A1, near), ShouldResemble, board.RookAttacks(board.A1, far))
+		})
+		Convey("The occupied square itself should make no difference", func() {
+			So(board.RookAttacks(board.H8, *board.NewBitboard(board.H8)), ShouldResemble,
+				board.RookAttacks(board.H8, empty))
+		})
+	})
+}
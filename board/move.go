@@ -0,0 +1,163 @@
+package board
+
+import (
+	"strings"
+
+	"github.com/peteches/ChessEngine/errors"
+)
+
+/*
+Move is a single move packed into 32 bits.
+
+	bits  0-5  source square index
+	bits  6-11 destination square index
+	bits 12-14 PieceType promoted to, NoPieceType if not a promotion
+	bits 15-18 MoveFlags
+
+The zero value is the null move, which is written as "0000" in UCI.
+*/
+type Move uint32
+
+// MoveFlag records extra information about a move which cannot be derived
+// from the source and destination squares alone.
+type MoveFlag uint32
+
+const (
+	moveDstShift       = 6
+	movePromotionShift = 12
+	moveSquareMask     = 0x3f
+	movePromotionMask  = 0x7
+)
+
+const (
+	Capture MoveFlag = 1 << (iota + 15)
+	Castle
+	EnPassant
+	DoublePush
+)
+
+// NullMove is the move that does nothing.
+const NullMove Move = 0
+
+// NewMove returns a Move from src to dst. promotion should be NoPieceType
+// unless a pawn is promoting.
+func NewMove(src, dst Square, promotion PieceType, flags ...MoveFlag) Move {
+	move := Move(src.Index()) |
+		Move(dst.Index())<<moveDstShift |
+		Move(promotion)<<movePromotionShift
+
+	for _, flag := range flags {
+		move |= Move(flag)
+	}
+
+	return move
+}
+
+func (m Move) From() Square {
+	return SquareAt(int(m & moveSquareMask))
+}
+
+func (m Move) To() Square {
+	return SquareAt(int(m >> moveDstShift & moveSquareMask))
+}
+
+func (m Move) Promotion() PieceType {
+	return PieceType(m >> movePromotionShift & movePromotionMask)
+}
+
+// Is returns true if the move has flag set.
+func (m Move) Is(flag MoveFlag) bool {
+	return MoveFlag(m)&flag != 0
+}
+
+func (m Move) IsCapture() bool {
+	return m.Is(Capture)
+}
+
+func (m Move) IsCastle() bool {
+	return m.Is(Castle)
+}
+
+func (m Move) IsEnPassant() bool {
+	return m.Is(EnPassant)
+}
+
+func (m Move) IsDoublePush() bool {
+	return m.Is(DoublePush)
+}
+
+// SameMove returns true if both moves go from the same source square to the
+// same destination square with the same promotion, regardless of flags.
+// Moves parsed from UCI carry no flags so this is how they should be
+// compared to generated moves.
+func (m Move) SameMove(other Move) bool {
+	const mask = moveSquareMask | moveSquareMask<<moveDstShift | movePromotionMask<<movePromotionShift
+
+	return m&mask == other&mask
+}
+
+// String returns the move in the long algebraic notation used by UCI,
+// e.g. "e2e4" or "e7e8q".
+func (m Move) String() string {
+	if m == NullMove {
+		return "0000"
+	}
+
+	move := strings.ToLower(m.From().String() + m.To().String())
+
+	if m.Promotion() != NoPieceType {
+		move += strings.ToLower(m.Promotion().String())
+	}
+
+	return move
+}
+
+// ParseMove parses a move in UCI long algebraic notation. As UCI moves carry
+// no information about the position they are played in, the returned move
+// has no flags set.
+func ParseMove(uciMove string) (Move, *errors.MoveError) {
+	if uciMove == "0000" {
+		return NullMove, nil
+	}
+
+	//nolint:gomnd // two squares with an optional promotion piece
+	if len(uciMove) != 4 && len(uciMove) != 5 {
+		return NullMove, &errors.MoveError{
+			Err:  "Moves should be two squares followed by an optional promotion piece, e.g. e7e8q.",
+			Move: uciMove,
+		}
+	}
+
+	src, srcOk := BoardMatrixStoI[strings.ToUpper(uciMove[0:2])]
+	dst, dstOk := BoardMatrixStoI[strings.ToUpper(uciMove[2:4])]
+
+	if !srcOk || !dstOk {
+		return NullMove, &errors.MoveError{
+			Err:  "Invalid square.",
+			Move: uciMove,
+		}
+	}
+
+	promotion := NoPieceType
+
+	//nolint:gomnd // the promotion piece follows the two squares
+	if len(uciMove) == 5 {
+		switch strings.ToLower(uciMove[4:]) {
+		case "q":
+			promotion = QueenType
+		case "r":
+			promotion = RookType
+		case "b":
+			promotion = BishopType
+		case "n":
+			promotion = KnightType
+		default:
+			return NullMove, &errors.MoveError{
+				Err:  "Invalid promotion piece, should be one of [qrbn].",
+				Move: uciMove,
+			}
+		}
+	}
+
+	return NewMove(src, dst, promotion), nil
+}
@@ -0,0 +1,106 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // convey testing is verbose
+func TestMove(t *testing.T) {
+	Convey("Given a NewMove() function", t, func() {
+		Convey("It should pack the source and destination squares", func() {
+			for _, src := range board.AllSquares {
+				for _, dst := range board.AllSquares {
+					move := board.NewMove(src, dst, board.NoPieceType)
+					So(move.From(), ShouldEqual, src)
+					So(move.To(), ShouldEqual, dst)
+					So(move.Promotion(), ShouldEqual, board.NoPieceType)
+				}
+			}
+		})
+		Convey("It should pack the promotion piece", func() {
+			for _, pt := range []board.PieceType{board.QueenType, board.RookType, board.BishopType, board.KnightType} {
+				move := board.NewMove(board.B7, board.A8, pt, board.Capture)
+				So(move.Promotion(), ShouldEqual, pt)
+				So(move.From(), ShouldEqual, board.B7)
+				So(move.To(), ShouldEqual, board.A8)
+				So(move.IsCapture(), ShouldBeTrue)
+			}
+		})
+		Convey("It should pack any flags", func() {
+			move := board.NewMove(board.E2, board.E4, board.NoPieceType)
+			So(move.IsCapture(), ShouldBeFalse)
+			So(move.IsCastle(), ShouldBeFalse)
+			So(move.IsEnPassant(), ShouldBeFalse)
+			So(move.IsDoublePush(), ShouldBeFalse)
+
+			move = board.NewMove(board.E2, board.E4, board.NoPieceType, board.DoublePush)
+			So(move.IsDoublePush(), ShouldBeTrue)
+			So(move.IsCapture(), ShouldBeFalse)
+
+			move = board.NewMove(board.E1, board.G1, board.NoPieceType, board.Castle)
+			So(move.IsCastle(), ShouldBeTrue)
+
+			move = board.NewMove(board.E5, board.D6, board.NoPieceType, board.Capture, board.EnPassant)
+			So(move.IsCapture(), ShouldBeTrue)
+			So(move.IsEnPassant(), ShouldBeTrue)
+			So(move.Is(board.Capture|board.EnPassant), ShouldBeTrue)
+		})
+	})
+	Convey("Given a Move", t, func() {
+		Convey("String() should return UCI long algebraic notation", func() {
+			So(board.NewMove(board.E2, board.E4, board.NoPieceType).String(), ShouldEqual, "e2e4")
+			So(board.NewMove(board.E7, board.E8, board.QueenType).String(), ShouldEqual, "e7e8q")
+			So(board.NewMove(board.A2, board.B1, board.KnightType).String(), ShouldEqual, "a2b1n")
+			So(board.NullMove.String(), ShouldEqual, "0000")
+		})
+		Convey("SameMove() should ignore flags", func() {
+			move := board.NewMove(board.E2, board.E4, board.NoPieceType, board.DoublePush)
+			So(move.SameMove(board.NewMove(board.E2, board.E4, board.NoPieceType)), ShouldBeTrue)
+			So(move.SameMove(board.NewMove(board.E2, board.E3, board.NoPieceType)), ShouldBeFalse)
+			promotion := board.NewMove(board.E7, board.E8, board.QueenType)
+			So(promotion.SameMove(board.NewMove(board.E7, board.E8, board.RookType)), ShouldBeFalse)
+		})
+	})
+	Convey("Given a ParseMove() function", t, func() {
+		Convey("It should parse UCI long algebraic notation", func() {
+			testCases := map[string]board.Move{
+				"e2e4":  board.NewMove(board.E2, board.E4, board.NoPieceType),
+				"E2E4":  board.NewMove(board.E2, board.E4, board.NoPieceType),
+				"a7a8q": board.NewMove(board.A7, board.A8, board.QueenType),
+				"h2h1N": board.NewMove(board.H2, board.H1, board.KnightType),
+				"0000":  board.NullMove,
+			}
+			for uci, expected := range testCases {
+				move, err := board.ParseMove(uci)
+				So(err, ShouldBeNil)
+				So(move, ShouldEqual, expected)
+			}
+		})
+		Convey("It should round trip with String()", func() {
+			for _, uci := range []string{"e2e4", "g1f3", "e1g1", "b7a8r", "d2d1b"} {
+				move, err := board.ParseMove(uci)
+				So(err, ShouldBeNil)
+				So(move.String(), ShouldEqual, uci)
+			}
+		})
+		Convey("It should return a MoveError for anything else", func() {
+			testCases := map[string]string{
+				"e2":     "Moves should be two squares followed by an optional promotion piece, e.g. e7e8q.",
+				"e2-e4":  "Invalid square.",
+				"e2e4qq": "Moves should be two squares followed by an optional promotion piece, e.g. e7e8q.",
+				"i2e4":   "Invalid square.",
+				"e2e9":   "Invalid square.",
+				"e7e8k":  "Invalid promotion piece, should be one of [qrbn].",
+			}
+			for uci, errMsg := range testCases {
+				move, err := board.ParseMove(uci)
+				So(move, ShouldEqual, board.NullMove)
+				So(err, ShouldResemble, &errors.MoveError{Err: errMsg, Move: uci})
+			}
+		})
+	})
+}
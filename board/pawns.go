@@ -89,3 +89,11 @@ func (q *Pawns) ValidMove(src, dst Square) bool {
 func (q *Pawns) Positions() *BitBoard {
 	return q.BitBoard
 }
+
+func (q *Pawns) Type() PieceType {
+	return PawnType
+}
+
+func (q *Pawns) Side() Side {
+	return q.Colour
+}
@@ -1,5 +1,7 @@
 package board
 
+import "strings"
+
 /*
 Piece is the interface that all pieces on the board must adhere to.
 
@@ -12,4 +14,71 @@ type Piece interface {
 	String() string
 	ValidMove(Square, Square) bool
 	Positions() *BitBoard
+	Type() PieceType
+	Side() Side
+}
+
+// PieceType identifies a kind of piece regardless of its colour.
+type PieceType uint8
+
+const (
+	NoPieceType PieceType = iota
+	PawnType
+	KnightType
+	BishopType
+	RookType
+	QueenType
+	KingType
+)
+
+// String returns the white Fen character for the piece type.
+func (pt PieceType) String() string {
+	switch pt {
+	case PawnType:
+		return "P"
+	case KnightType:
+		return "N"
+	case BishopType:
+		return "B"
+	case RookType:
+		return "R"
+	case QueenType:
+		return "Q"
+	case KingType:
+		return "K"
+	case NoPieceType:
+		return ""
+	default:
+		return ""
+	}
+}
+
+// ColouredPiece is a single piece of one colour, such as a white knight, as
+// found on a square of the board. The zero value, NoPiece, is an empty square.
+type ColouredPiece uint8
+
+const NoPiece ColouredPiece = 0
+
+// colourShift places the colour of a ColouredPiece above its PieceType.
+const colourShift = 3
+
+func NewColouredPiece(colour Side, pieceType PieceType) ColouredPiece {
+	return ColouredPiece(uint8(pieceType) | uint8(colour)<<colourShift)
+}
+
+func (cp ColouredPiece) Type() PieceType {
+	return PieceType(cp & (1<<colourShift - 1))
+}
+
+func (cp ColouredPiece) Colour() Side {
+	return Side(cp >> colourShift)
+}
+
+// String returns the Fen character for the piece, lower case for black.
+func (cp ColouredPiece) String() string {
+	if cp.Colour() == Black {
+		return strings.ToLower(cp.Type().String())
+	}
+
+	return cp.Type().String()
 }
@@ -3,6 +3,7 @@ package board_test
 import (
 	"testing"
 
+	"github.com/peteches/ChessEngine/board"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -11,4 +12,41 @@ func TestPiece(t *testing.T) {
 		Convey("It should have required fields", func() {
 		})
 	})
+	Convey("Given a PieceType", t, func() {
+		Convey("String() should return the white fen character", func() {
+			testCases := map[board.PieceType]string{
+				board.NoPieceType: "",
+				board.PawnType:    "P",
+				board.KnightType:  "N",
+				board.BishopType:  "B",
+				board.RookType:    "R",
+				board.QueenType:   "Q",
+				board.KingType:    "K",
+			}
+			for pt, expected := range testCases {
+				So(pt.String(), ShouldEqual, expected)
+			}
+		})
+	})
+	Convey("Given a ColouredPiece", t, func() {
+		Convey("It should combine a colour and a piece type", func() {
+			for _, side := range []board.Side{board.White, board.Black} {
+				for _, pt := range []board.PieceType{
+					board.PawnType, board.KnightType, board.BishopType,
+					board.RookType, board.QueenType, board.KingType,
+				} {
+					piece := board.NewColouredPiece(side, pt)
+					So(piece, ShouldNotEqual, board.NoPiece)
+					So(piece.Colour(), ShouldEqual, side)
+					So(piece.Type(), ShouldEqual, pt)
+				}
+			}
+		})
+		Convey("String() should return the fen character", func() {
+			So(board.NewColouredPiece(board.White, board.KnightType).String(), ShouldEqual, "N")
+			So(board.NewColouredPiece(board.Black, board.KnightType).String(), ShouldEqual, "n")
+			So(board.NewColouredPiece(board.Black, board.QueenType).String(), ShouldEqual, "q")
+			So(board.NoPiece.String(), ShouldEqual, "")
+		})
+	})
 }
@@ -0,0 +1,125 @@
+package board
+
+import "math/bits"
+
+// Pin describes a piece pinned to its king by an enemy slider.
+type Pin struct {
+	Pinned Square
+	Pinner Square
+	// Ray holds the squares between the king and the pinner, and the
+	// pinner itself. The pinned piece may only move along it.
+	Ray BitBoard
+}
+
+// Between returns the squares strictly between src and dst if they share a
+// rank, file or diagonal, and an empty bitboard otherwise.
+func Between(src, dst Square) BitBoard {
+	srcOnly, dstOnly := BitBoard{Board: uint64(dst)}, BitBoard{Board: uint64(src)}
+
+	switch {
+	case RookAttacks(src, BitBoard{}).Board&uint64(dst) != 0:
+		return BitBoard{Board: RookAttacks(src, srcOnly).Board & RookAttacks(dst, dstOnly).Board}
+	case BishopAttacks(src, BitBoard{}).Board&uint64(dst) != 0:
+		return BitBoard{Board: BishopAttacks(src, srcOnly).Board & BishopAttacks(dst, dstOnly).Board}
+	default:
+		return BitBoard{}
+	}
+}
+
+// KingSquare returns the square of side's king, or 0 if it has none.
+func (b *Board) KingSquare(side Side) Square {
+	king, _, _, _, _, _ := b.sidePieces(side)
+
+	return Square(king.BitBoard.Board & -king.BitBoard.Board)
+}
+
+// Checkers returns the squares of the pieces giving check to side's king.
+func (b *Board) Checkers(side Side) BitBoard {
+	kingSqr := b.KingSquare(side)
+	if kingSqr == 0 {
+		return BitBoard{}
+	}
+
+	return b.AttackersTo(kingSqr, other(side))
+}
+
+// KingDanger returns the squares side's king cannot move to because the
+// opponent attacks them. The king is taken off the board first so it cannot
+// step back along the line of a slider checking it.
+func (b *Board) KingDanger(side Side) BitBoard {
+	occupied := b.Occupancy()
+	occupied.Board &^= uint64(b.KingSquare(side))
+
+	return b.attackMap(other(side), occupied)
+}
+
+// Pins returns the pieces of side which are pinned to side's king.
+func (b *Board) Pins(side Side) []Pin {
+	pins := []Pin{}
+
+	for _, pin := range b.lonelyBlockers(b.KingSquare(side), other(side)) {
+		if b.OccupiedBySide(pin.Pinned, side) {
+			pins = append(pins, pin)
+		}
+	}
+
+	return pins
+}
+
+// DiscoveredCheckCandidates returns side's pieces which stand between one of
+// side's sliders and the opponent's king. Moving one off that line gives
+// check.
+func (b *Board) DiscoveredCheckCandidates(side Side) BitBoard {
+	candidates := BitBoard{}
+
+	for _, pin := range b.lonelyBlockers(b.KingSquare(other(side)), side) {
+		if b.OccupiedBySide(pin.Pinned, side) {
+			candidates.Board |= uint64(pin.Pinned)
+		}
+	}
+
+	return candidates
+}
+
+// lonelyBlockers finds the attacking side's sliders which would attack kingSqr
+// but for a single piece of either colour in the way.
+func (b *Board) lonelyBlockers(kingSqr Square, attackingSide Side) []Pin {
+	blockers := []Pin{}
+
+	if kingSqr == 0 {
+		return blockers
+	}
+
+	_, queens, rooks, bishops, _, _ := b.sidePieces(attackingSide)
+	snipers := BitBoard{
+		Board: RookAttacks(kingSqr, BitBoard{}).Board&(rooks.BitBoard.Board|queens.BitBoard.Board) |
+			BishopAttacks(kingSqr, BitBoard{}).Board&(bishops.BitBoard.Board|queens.BitBoard.Board),
+	}
+	occupied := b.Occupancy()
+
+	for snipers.Board != 0 {
+		sniper := snipers.PopSquare()
+		between := Between(kingSqr, sniper)
+		inTheWay := between.Board & occupied.Board
+
+		if bits.OnesCount64(inTheWay) != 1 {
+			continue
+		}
+
+		blockers = append(blockers, Pin{
+			Pinned: Square(inTheWay),
+			Pinner: sniper,
+			Ray:    BitBoard{Board: between.Board | uint64(sniper)},
+		})
+	}
+
+	return blockers
+}
+
+func other(side Side) Side {
+	if side == White {
+		return Black
+	}
+
+	return White
+}
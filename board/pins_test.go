@@ -0,0 +1,77 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBetween(t *testing.T) {
+	Convey("Given the Between() function", t, func() {
+		Convey("It should return the squares between two squares on a line", func() {
+			So(board.Between(board.A1, board.A4), ShouldResemble, *board.NewBitboard(board.A2, board.A3))
+			So(board.Between(board.H8, board.B8), ShouldResemble,
+				*board.NewBitboard(board.C8, board.D8, board.E8, board.F8, board.G8))
+			So(board.Between(board.C1, board.F4), ShouldResemble, *board.NewBitboard(board.D2, board.E3))
+			So(board.Between(board.H2, board.E5), ShouldResemble, *board.NewBitboard(board.G3, board.F4))
+		})
+		Convey("It should return nothing for adjacent squares or squares not on a line", func() {
+			So(board.Between(board.E4, board.E5), ShouldResemble, board.BitBoard{})
+			So(board.Between(board.E4, board.F6), ShouldResemble, board.BitBoard{})
+			So(board.Between(board.A1, board.H7), ShouldResemble, board.BitBoard{})
+		})
+	})
+}
+
+//nolint:funlen // convey testing is verbose
+func TestPins(t *testing.T) {
+	Convey("Given a board", t, func() {
+		testBoard := board.NewBoard()
+		Convey("KingSquare() should return the square of the king", func() {
+			So(testBoard.KingSquare(board.White), ShouldEqual, 0)
+			So(testBoard.SetPieces("4k3/8/8/8/8/8/8/4K3"), ShouldBeNil)
+			So(testBoard.KingSquare(board.White), ShouldEqual, board.E1)
+			So(testBoard.KingSquare(board.Black), ShouldEqual, board.E8)
+		})
+		Convey("Checkers() should return the pieces giving check", func() {
+			So(testBoard.SetPieces("4k3/8/8/8/8/8/8/4K3"), ShouldBeNil)
+			So(testBoard.Checkers(board.White), ShouldResemble, board.BitBoard{})
+			So(testBoard.SetPieces("4k3/8/8/8/1b6/3n4/8/4K3"), ShouldBeNil)
+			So(testBoard.Checkers(board.White), ShouldResemble, *board.NewBitboard(board.B4, board.D3))
+		})
+		Convey("KingDanger() should include squares behind the king on a checking slider's line", func() {
+			So(testBoard.SetPieces("4r2k/8/8/8/8/8/8/4K3"), ShouldBeNil)
+			danger := testBoard.KingDanger(board.White)
+			So(danger.Occupied(board.E2), ShouldBeTrue)
+			So(danger.Occupied(board.D1), ShouldBeFalse)
+			attacks := testBoard.AttackMap(board.Black)
+			So(attacks.Occupied(board.E1), ShouldBeTrue)
+		})
+		Convey("Pins() should return pieces pinned to their king with the pin ray", func() {
+			So(testBoard.SetPieces("4r2k/8/8/b7/1P6/2N5/4B3/4K3"), ShouldBeNil)
+			pins := testBoard.Pins(board.White)
+			So(pins, ShouldHaveLength, 1)
+			So(pins[0], ShouldResemble, board.Pin{
+				Pinned: board.E2,
+				Pinner: board.E8,
+				Ray:    *board.NewBitboard(board.E2, board.E3, board.E4, board.E5, board.E6, board.E7, board.E8),
+			})
+			Convey("But not pieces shielded by a second piece", func() {
+				So(testBoard.SetPieces("4r2k/4P3/8/b7/1P6/2N5/4B3/4K3"), ShouldBeNil)
+				So(testBoard.Pins(board.White), ShouldBeEmpty)
+			})
+			Convey("Or pieces of the pinning side", func() {
+				So(testBoard.SetPieces("4r2k/8/4n3/8/8/8/8/4K3"), ShouldBeNil)
+				So(testBoard.Pins(board.White), ShouldBeEmpty)
+			})
+		})
+		Convey("DiscoveredCheckCandidates() should return pieces blocking their own slider", func() {
+			So(testBoard.SetPieces("4k3/8/8/4N3/8/8/8/B3RK2"), ShouldBeNil)
+			So(testBoard.DiscoveredCheckCandidates(board.White), ShouldResemble, *board.NewBitboard(board.E5))
+			So(testBoard.SetPieces("4k3/8/4n3/4N3/8/8/8/4RK2"), ShouldBeNil)
+			So(testBoard.DiscoveredCheckCandidates(board.White), ShouldResemble, board.BitBoard{})
+			So(testBoard.DiscoveredCheckCandidates(board.Black), ShouldResemble, board.BitBoard{})
+		})
+	})
+}
@@ -35,42 +35,20 @@ func (q *Queens) ValidMove(src, dst Square) bool {
 		Str("destination", dst.String()).
 		Msg("Validating Queen move")
 
-	for _, validDst := range DiagonalMoves(src) {
-		if dst == validDst {
-			log.Debug().
-				Str("source", src.String()).
-				Str("destination", dst.String()).
-				Msg("Dest is a valid diagonal Move")
+	// on an empty board a slider attacks every square it can move to.
+	attacks := QueenAttacks(src, BitBoard{})
 
-			return true
-		}
-	}
-
-	for _, validDst := range OrthaganolFileMoves(src) {
-		if dst == validDst {
-			log.Debug().
-				Str("source", src.String()).
-				Str("destination", dst.String()).
-				Msg("Dest is a valid orthagonal File Move")
-
-			return true
-		}
-	}
-
-	for _, validDst := range OrthaganolRankMoves(src) {
-		if dst == validDst {
-			log.Debug().
-				Str("source", src.String()).
-				Str("destination", dst.String()).
-				Msg("Dest is a valid orthagonal Rank Move")
-
-			return true
-		}
-	}
-
-	return false
+	return attacks.Occupied(dst)
 }
 
 func (q *Queens) Positions() *BitBoard {
 	return q.BitBoard
 }
+
+func (q *Queens) Type() PieceType {
+	return QueenType
+}
+
+func (q *Queens) Side() Side {
+	return q.Colour
+}
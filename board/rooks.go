@@ -35,31 +35,20 @@ func (q *Rooks) ValidMove(src, dst Square) bool {
 		Str("destination", dst.String()).
 		Msg("Validating Queen move")
 
-	for _, validDst := range OrthaganolFileMoves(src) {
-		if dst == validDst {
-			log.Debug().
-				Str("source", src.String()).
-				Str("destination", dst.String()).
-				Msg("Dest is a valid orthagonal File Move")
+	// on an empty board a slider attacks every square it can move to.
+	attacks := RookAttacks(src, BitBoard{})
 
-			return true
-		}
-	}
-
-	for _, validDst := range OrthaganolRankMoves(src) {
-		if dst == validDst {
-			log.Debug().
-				Str("source", src.String()).
-				Str("destination", dst.String()).
-				Msg("Dest is a valid orthagonal Rank Move")
-
-			return true
-		}
-	}
-
-	return false
+	return attacks.Occupied(dst)
 }
 
 func (q *Rooks) Positions() *BitBoard {
 	return q.BitBoard
 }
+
+func (q *Rooks) Type() PieceType {
+	return RookType
+}
+
+func (q *Rooks) Side() Side {
+	return q.Colour
+}
@@ -0,0 +1,111 @@
+package board
+
+// seeValues are the piece values used by SEE, in centipawns.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var seeValues = [...]int{
+	NoPieceType: 0,
+	PawnType:    100,
+	KnightType:  320,
+	BishopType:  330,
+	RookType:    500,
+	QueenType:   900,
+	KingType:    20000,
+}
+
+// leastValuableAttacker returns the square and type of side's least valuable
+// piece in attackers.
+func (b *Board) leastValuableAttacker(attackers BitBoard, side Side) (Square, PieceType) {
+	for pieceType := PawnType; pieceType <= KingType; pieceType++ {
+		if pieces := attackers.Board & b.TypeOccupancy(pieceType).Board; pieces != 0 {
+			return (&BitBoard{Board: pieces}).PopSquare(), pieceType
+		}
+	}
+
+	return 0, NoPieceType
+}
+
+// SEE returns the Static Exchange Evaluation of move: the material won, in
+// centipawns, once the captures on its destination have been played out with
+// each side taking with its least valuable piece and stopping when taking
+// again would lose material. Pieces behind those taking join in as they are
+// uncovered and pawns promote as they reach the last rank. Pins are not
+// taken into account.
+func (b *Board) SEE(move Move) int {
+	src, dst := move.From(), move.To()
+	mover := b.PieceAt(src)
+	side := mover.Colour()
+	occupied := b.Occupancy()
+
+	// gains[depth] is the material won by the side making the capture at
+	// depth if the other side stops taking after it.
+	gains := []int{seeValues[b.PieceAt(dst).Type()]}
+	onSquare := seeValues[mover.Type()]
+
+	if move.IsEnPassant() {
+		gains[0] = seeValues[PawnType]
+		occupied.Board &^= uint64(EnPassantCapture(side, dst))
+	}
+
+	if promotion := move.Promotion(); promotion != NoPieceType {
+		gains[0] += seeValues[promotion] - seeValues[PawnType]
+		onSquare = seeValues[promotion]
+	}
+
+	promotes := dst.Rank() == FirstRank || dst.Rank() == EighthRank
+	occupied.Board &^= uint64(src)
+
+	for {
+		side = other(side)
+
+		attackers := b.attackersTo(dst, side, occupied)
+		if attackers.Board == 0 {
+			break
+		}
+
+		sqr, pieceType := b.leastValuableAttacker(attackers, side)
+		occupied.Board &^= uint64(sqr)
+
+		// a king cannot take a defended piece.
+		if pieceType == KingType && b.attackersTo(dst, other(side), occupied).Board != 0 {
+			break
+		}
+
+		gain := onSquare - gains[len(gains)-1]
+		onSquare = seeValues[pieceType]
+
+		if pieceType == PawnType && promotes {
+			gain += seeValues[QueenType] - seeValues[PawnType]
+			onSquare = seeValues[QueenType]
+		}
+
+		gains = append(gains, gain)
+	}
+
+	// each side only takes if it does better than stopping.
+	for depth := len(gains) - 1; depth > 0; depth-- {
+		if -gains[depth] < gains[depth-1] {
+			gains[depth-1] = -gains[depth]
+		}
+	}
+
+	return gains[0]
+}
+
+// SEEGE returns true if the Static Exchange Evaluation of move is at least
+// threshold, as used to prune captures which lose material.
+func (b *Board) SEEGE(move Move, threshold int) bool {
+	return b.SEE(move) >= threshold
+}
+
+// EnPassantCapture returns the square of the pawn taken by side capturing en
+// passant on dst, directly behind dst from side's point of view.
+func EnPassantCapture(side Side, dst Square) Square {
+	//nolint:gomnd // 8 is the number of squares between Ranks
+	if side == White {
+		return dst >> 8
+	}
+
+	//nolint:gomnd // 8 is the number of squares between Ranks
+	return dst << 8
+}
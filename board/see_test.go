@@ -0,0 +1,94 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // convey testing is verbose
+func TestSEE(t *testing.T) {
+	Convey("Given SEE()", t, func() {
+		testBoard := board.NewBoard()
+		for name, test := range map[string]struct {
+			pieces string
+			move   board.Move
+			see    int
+		}{
+			"A capture of an undefended piece should win it": {
+				pieces: "1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3",
+				move:   board.NewMove(board.E1, board.E5, board.NoPieceType, board.Capture),
+				see:    100,
+			},
+			"A capture of a defended pawn by a knight should lose the knight for the pawn": {
+				pieces: "1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3",
+				move:   board.NewMove(board.D3, board.E5, board.NoPieceType, board.Capture),
+				see:    -220,
+			},
+			"A quiet move to a safe square should be even": {
+				pieces: "4k3/8/8/8/8/8/8/R3K3",
+				move:   board.NewMove(board.A1, board.A5, board.NoPieceType),
+				see:    0,
+			},
+			"A quiet move to an attacked square should lose the piece": {
+				pieces: "4k3/8/1p6/8/8/8/8/R3K3",
+				move:   board.NewMove(board.A1, board.A5, board.NoPieceType),
+				see:    -500,
+			},
+			"A rook behind the capturing rook should join in": {
+				pieces: "3rk3/8/8/3p4/8/8/3R4/3R2K1",
+				move:   board.NewMove(board.D2, board.D5, board.NoPieceType, board.Capture),
+				see:    100,
+			},
+			"Without the rook behind the exchange should lose": {
+				pieces: "3rk3/8/8/3p4/8/8/3R4/6K1",
+				move:   board.NewMove(board.D2, board.D5, board.NoPieceType, board.Capture),
+				see:    -400,
+			},
+			"A queen behind a bishop should join in on the diagonal": {
+				pieces: "4k3/8/2n5/8/4B3/5Q2/8/4K3",
+				move:   board.NewMove(board.E4, board.C6, board.NoPieceType, board.Capture),
+				see:    320,
+			},
+			"En passant should win the pawn": {
+				pieces: "4k3/8/8/3pP3/8/8/8/4K3",
+				move:   board.NewMove(board.E5, board.D6, board.NoPieceType, board.Capture, board.EnPassant),
+				see:    100,
+			},
+			"A promotion should win the difference between the pieces": {
+				pieces: "4k3/1P6/8/8/8/8/8/4K3",
+				move:   board.NewMove(board.B7, board.B8, board.QueenType),
+				see:    800,
+			},
+			"A promotion which is recaptured should only win what it took": {
+				pieces: "rk6/1P6/8/8/8/8/8/4K3",
+				move:   board.NewMove(board.B7, board.A8, board.QueenType, board.Capture),
+				see:    400,
+			},
+			"A pawn recapturing on the last rank should promote": {
+				pieces: "4k3/2R5/8/8/8/8/1p6/2r3K1",
+				move:   board.NewMove(board.C7, board.C1, board.NoPieceType, board.Capture),
+				see:    -800,
+			},
+			"A king should not take a defended piece": {
+				pieces: "8/8/3k4/3p4/8/8/3Q4/3RK3",
+				move:   board.NewMove(board.D2, board.D5, board.NoPieceType, board.Capture),
+				see:    100,
+			},
+			"A king should take an undefended piece": {
+				pieces: "8/8/3k4/3p4/8/8/3Q4/4K3",
+				move:   board.NewMove(board.D2, board.D5, board.NoPieceType, board.Capture),
+				see:    -800,
+			},
+		} {
+			test := test
+			Convey(name, func() {
+				So(testBoard.SetPieces(test.pieces), ShouldBeNil)
+				So(testBoard.SEE(test.move), ShouldEqual, test.see)
+				So(testBoard.SEEGE(test.move, test.see), ShouldBeTrue)
+				So(testBoard.SEEGE(test.move, test.see+1), ShouldBeFalse)
+			})
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package board
 
-import "github.com/rs/zerolog/log"
+import (
+	"math/bits"
+
+	"github.com/rs/zerolog/log"
+)
 
 const TotalSquares = 64
 
@@ -32,6 +36,17 @@ func (s Square) String() string {
 	return BoardMatrixItoS[s]
 }
 
+// Index returns the position of the square on the board, from 0 for A1 to 63
+// for H8.
+func (s Square) Index() int {
+	return bits.TrailingZeros64(uint64(s))
+}
+
+// SquareAt returns the square with the given Index.
+func SquareAt(idx int) Square {
+	return Square(1 << idx)
+}
+
 func (s *Square) File() uint8 {
 	switch *s {
 	case A1, A2, A3, A4, A5, A6, A7, A8:
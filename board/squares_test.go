@@ -247,6 +247,20 @@ func TestSquare(t *testing.T) {
 				So(sqr.OnEdge(), ShouldEqual, expectedResult)
 			}
 		})
+		Convey("Index() should return the position of the square from A1", func() {
+			So(board.A1.Index(), ShouldEqual, 0)
+			So(board.H1.Index(), ShouldEqual, 7)
+			So(board.A2.Index(), ShouldEqual, 8)
+			So(board.E4.Index(), ShouldEqual, 28)
+			So(board.H8.Index(), ShouldEqual, 63)
+		})
+	})
+	Convey("Given a SquareAt() function", t, func() {
+		Convey("It should return the square with the given index", func() {
+			for _, sqr := range board.AllSquares {
+				So(board.SquareAt(sqr.Index()), ShouldEqual, sqr)
+			}
+		})
 	})
 }
 
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"math/bits"
+
+	"github.com/peteches/ChessEngine/board"
+)
+
+// sideCastlingRights lists the castling rights belonging to each side, king
+// side first.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var sideCastlingRights = map[board.Side][]uint8{
+	board.White: {WhiteKingSideAllowed, WhiteQueenSideAllowed},
+	board.Black: {BlackKingSideAllowed, BlackQueenSideAllowed},
+}
+
+// allCastlingRights lists the castling rights in the order FEN writes them.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var allCastlingRights = []uint8{
+	WhiteKingSideAllowed, WhiteQueenSideAllowed, BlackKingSideAllowed, BlackQueenSideAllowed,
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var castlingRightChars = map[uint8]rune{
+	WhiteKingSideAllowed:  'K',
+	WhiteQueenSideAllowed: 'Q',
+	BlackKingSideAllowed:  'k',
+	BlackQueenSideAllowed: 'q',
+}
+
+// castlingTarget holds the square the rook starts on in standard chess and
+// the squares the king and rook finish on, which are the same in Chess960.
+type castlingTarget struct {
+	rook    board.Square
+	kingDst board.Square
+	rookDst board.Square
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var castlingTargets = map[uint8]castlingTarget{
+	WhiteKingSideAllowed:  {rook: board.H1, kingDst: board.G1, rookDst: board.F1},
+	WhiteQueenSideAllowed: {rook: board.A1, kingDst: board.C1, rookDst: board.D1},
+	BlackKingSideAllowed:  {rook: board.H8, kingDst: board.G8, rookDst: board.F8},
+	BlackQueenSideAllowed: {rook: board.A8, kingDst: board.C8, rookDst: board.D8},
+}
+
+// castlingPath describes the squares involved in castling.
+type castlingPath struct {
+	right   uint8
+	king    board.Square
+	rook    board.Square
+	dst     board.Square
+	rookDst board.Square
+	// empty must hold no pieces other than the castling king and rook.
+	empty board.BitBoard
+	// passes are the squares the king moves over, none may be attacked.
+	passes board.BitBoard
+}
+
+func isKingSide(right uint8) bool {
+	return right&(WhiteKingSideAllowed|BlackKingSideAllowed) != 0
+}
+
+func rightSide(right uint8) board.Side {
+	if right&(WhiteKingSideAllowed|WhiteQueenSideAllowed) != 0 {
+		return board.White
+	}
+
+	return board.Black
+}
+
+// span returns the squares from src to dst inclusive.
+func span(src, dst board.Square) board.BitBoard {
+	squares := board.Between(src, dst)
+	squares.Board |= uint64(src | dst)
+
+	return squares
+}
+
+// castlingRook returns the square of the rook right castles with.
+func (p *Position) castlingRook(right uint8) board.Square {
+	if sqr := p.castlingRooks[bits.TrailingZeros8(right)]; sqr != 0 {
+		return sqr
+	}
+
+	return castlingTargets[right].rook
+}
+
+func (p *Position) setCastlingRook(right uint8, sqr board.Square) {
+	if sqr == castlingTargets[right].rook {
+		sqr = 0
+	}
+
+	p.castlingRooks[bits.TrailingZeros8(right)] = sqr
+}
+
+// outermostRook returns the rook furthest from the king on the wing right
+// castles to, which is the rook X-FEN's KQkq refer to, or 0 if there is none.
+func (p *Position) outermostRook(right uint8) board.Square {
+	side := rightSide(right)
+	kingSqr := p.Board.KingSquare(side)
+	kingDst := castlingTargets[right].kingDst
+	backRank := kingDst.Rank()
+
+	if kingSqr == 0 || kingSqr.Rank() != backRank {
+		return 0
+	}
+
+	rooks := p.Board.PieceOfType(side, board.RookType).Positions()
+	// Index is 0 to 63 so the file index is always 0 to 7.
+	rankStart := kingSqr.Index() - kingSqr.Index()%numFiles
+
+	for file := 0; file < numFiles; file++ {
+		sqr := board.SquareAt(rankStart + file)
+		if isKingSide(right) {
+			sqr = board.SquareAt(rankStart + numFiles - 1 - file)
+		}
+
+		if sqr == kingSqr {
+			return 0
+		}
+
+		if rooks.Occupied(sqr) {
+			return sqr
+		}
+	}
+
+	return 0
+}
+
+// castlingPath returns the path for right with the king on kingSqr.
+func (p *Position) castlingPath(right uint8, kingSqr board.Square) castlingPath {
+	target := castlingTargets[right]
+	path := castlingPath{
+		right:   right,
+		king:    kingSqr,
+		rook:    p.castlingRook(right),
+		dst:     target.kingDst,
+		rookDst: target.rookDst,
+	}
+
+	path.passes = span(path.king, path.dst)
+	path.empty.Board = (path.passes.Board | span(path.rook, path.rookDst).Board) &^ uint64(path.king|path.rook)
+
+	return path
+}
+
+// castlingPathOf returns the path for a castling move made by side.
+func (p *Position) castlingPathOf(side board.Side, move board.Move) castlingPath {
+	dst := move.To()
+	right := sideCastlingRights[side][1]
+
+	if dst.File() == board.GFile {
+		right = sideCastlingRights[side][0]
+	}
+
+	return p.castlingPath(right, move.From())
+}
+
+// castlingMoves returns the castling moves available to side. A king may not
+// castle out of, through or into check so these moves are fully legal.
+func (p *Position) castlingMoves(side board.Side) []board.Move {
+	moves := []board.Move{}
+	kingSqr := p.Board.KingSquare(side)
+	rooks := p.Board.PieceOfType(side, board.RookType).Positions()
+	occupied := p.Board.Occupancy()
+
+	for _, right := range sideCastlingRights[side] {
+		if p.CastlingRights&right == 0 || kingSqr == 0 {
+			continue
+		}
+
+		path := p.castlingPath(right, kingSqr)
+
+		if !rooks.Occupied(path.rook) || kingSqr.Rank() != path.dst.Rank() {
+			continue
+		}
+
+		if occupied.Board&path.empty.Board != 0 || p.anyAttacked(path.passes, opponent(side)) || p.exposesKing(side, path) {
+			continue
+		}
+
+		moves = append(moves, board.NewMove(path.king, path.dst, board.NoPieceType, board.Castle))
+	}
+
+	return moves
+}
+
+func (p *Position) anyAttacked(sqrs board.BitBoard, attackingSide board.Side) bool {
+	for sqrs.Board != 0 {
+		if p.Board.Attacked(sqrs.PopSquare(), attackingSide) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exposesKing reports whether moving the rook off the back rank leaves the
+// king attacked along it once castled. This only happens in Chess960, where
+// the rook can stand between the king's destination and an enemy rook.
+func (p *Position) exposesKing(side board.Side, path castlingPath) bool {
+	occupied := p.Board.Occupancy()
+	occupied.Board &^= uint64(path.king | path.rook)
+
+	enemy := opponent(side)
+	sliders := p.Board.PieceOfType(enemy, board.RookType).Positions().Board |
+		p.Board.PieceOfType(enemy, board.QueenType).Positions().Board
+
+	return board.RookAttacks(path.dst, occupied).Board&sliders != 0
+}
+
+// castlingRightsLost returns the castling rights lost by moving from src to
+// dst, which happens when the king moves or a castling rook moves or is
+// captured.
+func (p *Position) castlingRightsLost(side board.Side, src, dst board.Square) uint8 {
+	var lost uint8
+
+	if src == p.Board.KingSquare(side) {
+		lost |= sideCastlingRights[side][0] | sideCastlingRights[side][1]
+	}
+
+	for right := range castlingTargets {
+		if rook := p.castlingRook(right); src == rook || dst == rook {
+			lost |= right
+		}
+	}
+
+	return lost
+}
+
+// castlesAs returns true if move, parsed from UCI, names the castling move
+// legal by the king capturing its own rook, as Chess960 GUIs send it.
+func (p *Position) castlesAs(legal, move board.Move) bool {
+	if !legal.IsCastle() || move.From() != legal.From() || move.Promotion() != board.NoPieceType {
+		return false
+	}
+
+	return move.To() == p.castlingPathOf(board.Side(p.SideToMove), legal).rook
+}
+
+// UCIMove returns move in UCI notation. In Chess960 castling is written as the
+// king capturing its own rook.
+func (p *Position) UCIMove(move board.Move, chess960 bool) string {
+	if !chess960 || !move.IsCastle() {
+		return move.String()
+	}
+
+	path := p.castlingPathOf(board.Side(p.SideToMove), move)
+
+	return board.NewMove(path.king, path.rook, board.NoPieceType).String()
+}
+
+// castlingRightOf returns the castling right written as char, one of KQkq.
+func castlingRightOf(char rune) uint8 {
+	for right, rightChar := range castlingRightChars {
+		if rightChar == char {
+			return right
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestChess960Castling(t *testing.T) {
+	Convey("Given a Position", t, func() {
+		pos := NewPosition()
+		Convey("The castling field should accept", func() {
+			Convey("Shredder-FEN rook files, written as KQkq for the outermost rooks", func() {
+				So(pos.SetPositionFromFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1"), ShouldBeNil)
+				So(pos.String(), ShouldEqual, startingFen)
+				So(pos.castlingRooks, ShouldResemble, [4]board.Square{})
+			})
+			Convey("X-FEN, where KQkq find the outermost rook", func() {
+				So(pos.SetPositionFromFen("rkr5/8/8/8/8/8/8/5RKR w KQkq - 0 1"), ShouldBeNil)
+				So(pos.castlingRook(WhiteKingSideAllowed), ShouldEqual, board.H1)
+				So(pos.castlingRook(WhiteQueenSideAllowed), ShouldEqual, board.F1)
+				So(pos.castlingRook(BlackKingSideAllowed), ShouldEqual, board.C8)
+				So(pos.castlingRook(BlackQueenSideAllowed), ShouldEqual, board.A8)
+			})
+			Convey("a file for an inner rook, which is written back as its file", func() {
+				fen := "1k6/8/8/8/8/8/8/1K2R2R w E - 0 1"
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				So(pos.castlingRook(WhiteKingSideAllowed), ShouldEqual, board.E1)
+				So(pos.String(), ShouldEqual, fen)
+			})
+		})
+		Convey("A castling right should be rejected if it has no rook to castle with", func() {
+			So(pos.SetPositionFromFen("4k3/8/8/8/8/8/8/R5K1 w K - 0 1"), ShouldNotBeNil)
+		})
+		Convey("When the king already stands on its destination", func() {
+			fen := "4k3/8/8/8/8/8/8/R5KR w KQ - 0 1"
+			So(pos.SetPositionFromFen(fen), ShouldBeNil)
+			castle := board.NewMove(board.G1, board.G1, board.NoPieceType, board.Castle)
+			Convey("Only the rook should move when castling king side", func() {
+				So(uciMoves(pos.LegalMoves()), ShouldContain, "g1g1")
+				So(pos.UCIMove(castle, true), ShouldEqual, "g1h1")
+				So(pos.MakeMove(mustParseMove("g1h1")), ShouldBeNil)
+				So(pos.String(), ShouldEqual, "4k3/8/8/8/8/8/8/R4RK1 b - - 1 1")
+				So(pos.Hash(), ShouldEqual, pos.ComputeHash())
+				pos.UnmakeMove()
+				So(pos.String(), ShouldEqual, fen)
+				So(pos.Hash(), ShouldEqual, pos.ComputeHash())
+			})
+			Convey("The king should cross the board when castling queen side", func() {
+				So(pos.MakeMove(mustParseMove("g1a1")), ShouldBeNil)
+				So(pos.String(), ShouldEqual, "4k3/8/8/8/8/8/8/2KR3R b - - 1 1")
+			})
+		})
+		Convey("When the king lands on the rook's square", func() {
+			fen := "4k3/8/8/8/8/8/8/1R1K4 w Q - 0 1"
+			So(pos.SetPositionFromFen(fen), ShouldBeNil)
+			Convey("King captures rook notation should castle", func() {
+				So(pos.MakeMove(mustParseMove("d1b1")), ShouldBeNil)
+				So(pos.String(), ShouldEqual, "4k3/8/8/8/8/8/8/2KR4 b - - 1 1")
+				So(pos.Board.PieceAt(board.C1), ShouldEqual, board.NewColouredPiece(board.White, board.KingType))
+				pos.UnmakeMove()
+				So(pos.String(), ShouldEqual, fen)
+				So(pos.Board.PieceAt(board.B1), ShouldEqual, board.NewColouredPiece(board.White, board.RookType))
+			})
+			Convey("The standard notation should be an ordinary king move", func() {
+				So(pos.MakeMove(mustParseMove("d1c1")), ShouldBeNil)
+				So(pos.String(), ShouldEqual, "4k3/8/8/8/8/8/8/1RK5 b - - 1 1")
+			})
+		})
+		Convey("Castling should not be allowed if moving the rook exposes the king", func() {
+			So(pos.SetPositionFromFen("4k3/8/8/8/8/8/8/rR1K4 w B - 0 1"), ShouldBeNil)
+			for _, move := range pos.LegalMoves() {
+				So(move.IsCastle(), ShouldBeFalse)
+			}
+		})
+		Convey("Moving a Chess960 castling rook should lose its right", func() {
+			So(pos.SetPositionFromFen("1r2k1r1/8/8/8/8/8/8/1R2K1R1 w GBgb - 0 1"), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("g1g2")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "1r2k1r1/8/8/8/8/8/6R1/1R2K3 b Qkq - 1 1")
+			So(pos.MakeMove(mustParseMove("b8b1")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "4k1r1/8/8/8/8/8/6R1/1r2K3 w k - 0 2")
+		})
+		Convey("UCIMove() should only use king captures rook notation for Chess960", func() {
+			So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), ShouldBeNil)
+			castle := board.NewMove(board.E1, board.G1, board.NoPieceType, board.Castle)
+			So(pos.UCIMove(castle, false), ShouldEqual, "e1g1")
+			So(pos.UCIMove(castle, true), ShouldEqual, "e1h1")
+			So(pos.MakeMove(mustParseMove("e1h1")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 1")
+		})
+	})
+}
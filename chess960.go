@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/peteches/ChessEngine/errors"
+)
+
+const (
+	// Chess960Positions is the number of Fischer Random start positions.
+	Chess960Positions = 960
+	// StandardChess960Index is the Scharnagl index of the standard start
+	// position.
+	StandardChess960Index = 518
+	bishopFiles           = 4
+	queenSquares          = 6
+)
+
+// knightPlacements lists which of the five squares left after placing the
+// bishops and queen hold the knights, by the Scharnagl knight index.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var knightPlacements = [][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
+// Chess960BackRank returns white's back rank, from the A file to the H file,
+// for the Chess960 start position with the given Scharnagl index.
+func Chess960BackRank(index int) (string, *errors.Chess960IndexError) {
+	if index < 0 || index >= Chess960Positions {
+		return "", &errors.Chess960IndexError{Index: strconv.Itoa(index)}
+	}
+
+	rank := make([]byte, numFiles)
+
+	// bishops go on opposite colours, the light squared one first.
+	rank[index%bishopFiles*2+1] = 'B'
+	index /= bishopFiles
+	rank[index%bishopFiles*2] = 'B'
+	index /= bishopFiles
+
+	place := func(piece byte, nth int) {
+		for file := range rank {
+			if rank[file] != 0 {
+				continue
+			}
+
+			if nth == 0 {
+				rank[file] = piece
+
+				return
+			}
+
+			nth--
+		}
+	}
+
+	place('Q', index%queenSquares)
+	index /= queenSquares
+
+	knights := knightPlacements[index]
+	// placing the second knight first leaves the first one's index alone.
+	place('N', knights[1])
+	place('N', knights[0])
+
+	// the king always goes between the rooks.
+	place('R', 0)
+	place('K', 0)
+	place('R', 0)
+
+	return string(rank), nil
+}
+
+// Chess960Fen returns the FEN of the Chess960 start position with the given
+// Scharnagl index. Index 518 is the standard start position.
+func Chess960Fen(index int) (string, *errors.Chess960IndexError) {
+	return DoubleChess960Fen(index, index)
+}
+
+// DoubleChess960Fen returns the FEN of a Double Chess960 start position where
+// white and black's back ranks are chosen independently by Scharnagl index.
+func DoubleChess960Fen(white, black int) (string, *errors.Chess960IndexError) {
+	whiteRank, err := Chess960BackRank(white)
+	if err != nil {
+		return "", err
+	}
+
+	blackRank, err := Chess960BackRank(black)
+	if err != nil {
+		return "", err
+	}
+
+	// the rooks are always outermost so X-FEN's KQkq are enough.
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w KQkq - 0 1", strings.ToLower(blackRank), whiteRank), nil
+}
+
+// RandomChess960Fen returns the FEN of a Chess960 start position chosen by
+// rng.
+func RandomChess960Fen(rng *rand.Rand) string {
+	//nolint:errcheck // the index is always in range
+	fen, _ := Chess960Fen(rng.Intn(Chess960Positions))
+
+	return fen
+}
+
+// RandomDoubleChess960Fen returns the FEN of a Double Chess960 start position
+// with each side's back rank chosen by rng.
+func RandomDoubleChess960Fen(rng *rand.Rand) string {
+	//nolint:errcheck // the indexes are always in range
+	fen, _ := DoubleChess960Fen(rng.Intn(Chess960Positions), rng.Intn(Chess960Positions))
+
+	return fen
+}
+
+// chess960Index parses a Scharnagl index from the position command, which may
+// be "random" to have one chosen by rng.
+func chess960Index(arg string, rng *rand.Rand) (int, *errors.Chess960IndexError) {
+	if arg == "random" {
+		return rng.Intn(Chess960Positions), nil
+	}
+
+	index, err := strconv.Atoi(arg)
+	if err != nil || index < 0 || index >= Chess960Positions {
+		return 0, &errors.Chess960IndexError{Index: arg}
+	}
+
+	return index, nil
+}
+
+// chess960StartFen returns the FEN for the arguments to
+//
+//	position frc <index> ...
+//	position dfrc <white index> <black index> ...
+//
+// along with the arguments left over.
+func chess960StartFen(args []string, rng *rand.Rand) (string, []string, error) {
+	indexes := 1
+	if args[0] == "dfrc" {
+		indexes = 2
+	}
+
+	if len(args) < indexes+1 {
+		return "", nil, &errors.InvalidCommandError{
+			Cmd: strings.Join(append([]string{"position"}, args...), " "),
+			Err: fmt.Sprintf("Expected %d Chess960 position indexes.", indexes),
+		}
+	}
+
+	white, err := chess960Index(args[1], rng)
+	if err != nil {
+		return "", nil, err
+	}
+
+	black := white
+
+	if indexes == 2 {
+		if black, err = chess960Index(args[2], rng); err != nil {
+			return "", nil, err
+		}
+	}
+
+	fen, err := DoubleChess960Fen(white, black)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fen, args[indexes+1:], nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestChess960(t *testing.T) {
+	Convey("Given Chess960BackRank()", t, func() {
+		Convey("It should number the positions as Scharnagl does", func() {
+			for index, rank := range map[int]string{
+				0:   "BBQNNRKR",
+				1:   "BQNBNRKR",
+				518: "RNBQKBNR",
+				959: "RKRNNQBB",
+			} {
+				backRank, err := Chess960BackRank(index)
+				So(err, ShouldBeNil)
+				So(backRank, ShouldEqual, rank)
+			}
+		})
+		Convey("Every position should be different and follow the rules", func() {
+			seen := map[string]bool{}
+			for index := 0; index < Chess960Positions; index++ {
+				backRank, err := Chess960BackRank(index)
+				So(err, ShouldBeNil)
+				seen[backRank] = true
+
+				bishops := []int{strings.Index(backRank, "B"), strings.LastIndex(backRank, "B")}
+				So(bishops[0]%2, ShouldNotEqual, bishops[1]%2)
+
+				king := strings.Index(backRank, "K")
+				So(strings.Index(backRank, "R"), ShouldBeLessThan, king)
+				So(strings.LastIndex(backRank, "R"), ShouldBeGreaterThan, king)
+			}
+			So(seen, ShouldHaveLength, Chess960Positions)
+		})
+		Convey("It should reject an index out of range", func() {
+			_, err := Chess960BackRank(Chess960Positions)
+			So(err, ShouldResemble, &errors.Chess960IndexError{Index: "960"})
+			_, err = Chess960BackRank(-1)
+			So(err, ShouldResemble, &errors.Chess960IndexError{Index: "-1"})
+		})
+	})
+	Convey("Given Chess960Fen()", t, func() {
+		Convey("The standard index should give the standard start position", func() {
+			fen, err := Chess960Fen(StandardChess960Index)
+			So(err, ShouldBeNil)
+			So(fen, ShouldEqual, startingFen)
+		})
+		Convey("Every position should be a valid position with both sides able to castle", func() {
+			pos := NewPosition()
+			for index := 0; index < Chess960Positions; index++ {
+				fen, err := Chess960Fen(index)
+				So(err, ShouldBeNil)
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				So(pos.String(), ShouldEqual, fen)
+				So(pos.CastlingRights, ShouldEqual, WhiteKingSideAllowed|WhiteQueenSideAllowed|
+					BlackKingSideAllowed|BlackQueenSideAllowed)
+			}
+		})
+	})
+	Convey("Given DoubleChess960Fen()", t, func() {
+		Convey("Each side should have its own back rank", func() {
+			fen, err := DoubleChess960Fen(0, 959)
+			So(err, ShouldBeNil)
+			So(fen, ShouldEqual, "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1")
+			pos := NewPosition()
+			So(pos.SetPositionFromFen(fen), ShouldBeNil)
+		})
+		Convey("It should reject an index out of range for either side", func() {
+			_, err := DoubleChess960Fen(0, 1000)
+			So(err, ShouldResemble, &errors.Chess960IndexError{Index: "1000"})
+		})
+	})
+	Convey("Given a seeded random source", t, func() {
+		Convey("RandomChess960Fen() should repeat for the same seed", func() {
+			fen := RandomChess960Fen(rand.New(rand.NewSource(1)))
+			So(RandomChess960Fen(rand.New(rand.NewSource(1))), ShouldEqual, fen)
+			So(NewPosition().SetPositionFromFen(fen), ShouldBeNil)
+		})
+		Convey("RandomDoubleChess960Fen() should give valid positions", func() {
+			rng := rand.New(rand.NewSource(1))
+			for idx := 0; idx < 20; idx++ {
+				So(NewPosition().SetPositionFromFen(RandomDoubleChess960Fen(rng)), ShouldBeNil)
+			}
+		})
+	})
+	Convey("Given an engine", t, func() {
+		ctx, ctxCancel := context.WithCancel(context.Background())
+		toEng, frmEng, debug := engine(ctx)
+		Convey("position frc should set up a Chess960 start position", func() {
+			toEng <- "position frc 0 moves d1c3"
+			toEng <- "printPosition"
+			So(<-debug, ShouldEqual, "info string bbqnnrkr/pppppppp/8/8/8/2N5/PPPPPPPP/BBQ1NRKR b KQkq - 1 1")
+		})
+		Convey("position dfrc should set up each side independently", func() {
+			toEng <- "position dfrc 518 959"
+			toEng <- "printPosition"
+			So(<-debug, ShouldEqual, "info string rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+		})
+		Convey("position frc random should set up a valid position", func() {
+			toEng <- "position frc random"
+			toEng <- "printPosition"
+			fen := strings.TrimPrefix(<-debug, "info string ")
+			So(NewPosition().SetPositionFromFen(fen), ShouldBeNil)
+		})
+		Convey("position frc should turn on Chess960 castling notation", func() {
+			toEng <- "position frc 518 moves e2e4 e7e5 g1f3 g8f6 f1c4 f8c5"
+			toEng <- "go perft 1"
+			out := []string{}
+			for x := range frmEng {
+				out = append(out, x)
+				if strings.HasPrefix(x, "Nodes searched") {
+					break
+				}
+			}
+			So(out, ShouldContain, "e1h1: 1\n")
+			So(out, ShouldNotContain, "e1g1: 1\n")
+		})
+		Convey("position startpos after position frc should turn Chess960 castling notation off", func() {
+			toEng <- "position frc 518 moves e2e4 e7e5 g1f3 g8f6 f1c4 f8c5"
+			toEng <- "position startpos moves e2e4 e7e5 g1f3 g8f6 f1c4 f8c5"
+			toEng <- "go perft 1"
+			out := []string{}
+			for x := range frmEng {
+				out = append(out, x)
+				if strings.HasPrefix(x, "Nodes searched") {
+					break
+				}
+			}
+			So(out, ShouldContain, "e1g1: 1\n")
+			So(out, ShouldNotContain, "e1h1: 1\n")
+		})
+		Convey("position frc should report a bad index", func() {
+			toEng <- "position frc 960"
+			So(<-frmEng, ShouldEqual, "info string Error setting position: Invalid Chess960 position (960). "+
+				"Must be a number between 0 and 959 or random.")
+			toEng <- "position dfrc 1"
+			So(<-frmEng, ShouldEqual, "info string Error setting position: Invalid command (position dfrc 1). "+
+				"Expected 2 Chess960 position indexes.")
+		})
+		Reset(ctxCancel)
+	})
+}
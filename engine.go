@@ -3,9 +3,270 @@ package main
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
 )
 
+const startingFen = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// handlePosition sets up game from the arguments to the UCI position command
+//
+//	position [fen <fenstring> | startpos | frc <index> | dfrc <white index> <black index>] moves <move1> .... <movei>
+//
+// the fen keyword is optional. frc and dfrc set up Chess960 and Double Chess960
+// start positions by Scharnagl index, an index of random has one chosen by
+// rng. The engine writes castling in Chess960 notation until the next position
+// command that is not frc or dfrc.
+//
+// If the position continues game the new moves are played on it, keeping its
+// tags and annotations, otherwise game starts again. game is left as it was
+// if the arguments are invalid.
+func handlePosition(game *Game, args []string, rng *rand.Rand) error {
+	var err error
+
+	next := NewGameFromPosition(NewPosition())
+
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+		err = next.SetPositionFromFen(startingFen)
+		args = args[1:]
+	case len(args) > 0 && (args[0] == "frc" || args[0] == "dfrc"):
+		var fen string
+
+		fen, args, err = chess960StartFen(args, rng)
+		if err != nil {
+			return err
+		}
+
+		err = next.SetPositionFromFen(fen)
+	default:
+		if len(args) > 0 && args[0] == "fen" {
+			args = args[1:]
+		}
+
+		if len(args) < numFenElements {
+			return &errors.InvalidFenstringError{
+				Fen: strings.Join(args, " "),
+				Err: "Missing Fen elements",
+			}
+		}
+
+		err = next.SetPositionFromFen(strings.Join(args[:numFenElements], " "))
+		args = args[numFenElements:]
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 && args[0] == "moves" {
+		for _, uciMove := range args[1:] {
+			move, moveErr := board.ParseMove(uciMove)
+			if moveErr != nil {
+				moveErr.Fen = next.Position().String()
+
+				return moveErr
+			}
+
+			if moveErr = next.MakeMove(move); moveErr != nil {
+				return moveErr
+			}
+		}
+	}
+
+	if !continues(game, next) {
+		*game = *next
+
+		return nil
+	}
+
+	// the moves have already been played legally in next.
+	for _, move := range next.Moves()[game.Ply():] {
+		_ = game.MakeMove(move)
+	}
+
+	return nil
+}
+
+// continues returns true if next starts from the same position as game and
+// its moves begin with those played in game.
+func continues(game, next *Game) bool {
+	if game.start.String() != next.start.String() || next.Ply() < game.Ply() {
+		return false
+	}
+
+	played := next.Moves()
+
+	for idx, move := range game.Moves() {
+		if played[idx] != move {
+			return false
+		}
+	}
+
+	return true
+}
+
+// searchAnalysis is the analysis of the move chosen by a search, kept until
+// the GUI plays it.
+type searchAnalysis struct {
+	// key is the Zobrist key of the position searched.
+	key      uint64
+	move     board.Move
+	analysis Analysis
+}
+
+// newSearchAnalysis returns the analysis of result, found by searching
+// position for elapsed.
+func newSearchAnalysis(position *Position, result SearchResult, elapsed time.Duration) *searchAnalysis {
+	return &searchAnalysis{
+		key:  position.Hash(),
+		move: result.Move,
+		analysis: Analysis{
+			Score: result.Score,
+			Mate:  result.Mate(),
+			Depth: result.Depth,
+			Time:  elapsed,
+		},
+	}
+}
+
+// annotate stores the analysis on the move it chose, if that move has been
+// played in game from the position searched. It returns false if it has not.
+func (a *searchAnalysis) annotate(game *Game) bool {
+	keys, moves := game.Keys(), game.Moves()
+
+	// the position may have been repeated, the latest is the one searched.
+	for ply := len(moves) - 1; ply >= 0; ply-- {
+		if keys[ply] == a.key && moves[ply] == a.move {
+			annotation, _ := game.Annotate(ply + 1)
+			analysis := a.analysis
+			annotation.Analysis = &analysis
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// backgroundSearch is a search running alongside the engine's command loop,
+// so that the GUI can stop it.
+type backgroundSearch struct {
+	position *Position
+	chess960 bool
+	infinite bool
+	start    time.Time
+	stop     chan struct{}
+	done     chan SearchResult
+}
+
+// startSearch searches position within limits in the background, sending an
+// info line for each depth searched to frmEng. The result is sent on done once
+// the search is over, which for an infinite search is not until it is halted.
+func startSearch(ctx context.Context, position *Position, limits SearchLimits,
+	frmEng chan<- string, chess960 bool,
+) *backgroundSearch {
+	search := &backgroundSearch{
+		position: position,
+		chess960: chess960,
+		infinite: limits.Infinite,
+		start:    time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan SearchResult, 1),
+	}
+	limits.Stop = search.stop
+
+	go func() {
+		result := Search(position, limits, func(result SearchResult) {
+			select {
+			case frmEng <- infoLine(position, result, chess960):
+			case <-ctx.Done():
+			}
+		})
+
+		if limits.Infinite {
+			select {
+			case <-search.stop:
+			case <-ctx.Done():
+			}
+		}
+
+		search.done <- result
+	}()
+
+	return search
+}
+
+// halt stops the search and returns its result once it has finished.
+func (s *backgroundSearch) halt() SearchResult {
+	close(s.stop)
+
+	return <-s.done
+}
+
+// wait returns the result of the search once it has finished, halting it if
+// it is infinite as it would never finish otherwise.
+func (s *backgroundSearch) wait() SearchResult {
+	if s.infinite {
+		return s.halt()
+	}
+
+	return <-s.done
+}
+
+// handleSavePGN appends game to a PGN file, from the arguments to
+//
+//	savePGN <file>
+func handleSavePGN(game *Game, args []string) error {
+	if len(args) == 0 {
+		return &errors.InvalidCommandError{Cmd: "savePGN", Err: "Expected savePGN <file>."}
+	}
+
+	//nolint:gomnd // read and write for the owner, read for everyone else
+	file, err := os.OpenFile(strings.Join(args, " "), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err = game.WritePGN(file); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// parseSetOption returns the name and value from the arguments to the UCI
+// setoption command
+//
+//	setoption name <id> [value <x>]
+//
+// both of which may contain spaces.
+func parseSetOption(args []string) (string, string, error) {
+	if len(args) < 2 || args[0] != "name" {
+		return "", "", &errors.InvalidCommandError{
+			Cmd: strings.Join(append([]string{"setoption"}, args...), " "),
+			Err: "Expected setoption name <id> [value <x>].",
+		}
+	}
+
+	args = args[1:]
+
+	for idx, word := range args {
+		if word == "value" {
+			return strings.Join(args[:idx], " "), strings.Join(args[idx+1:], " "), nil
+		}
+	}
+
+	return strings.Join(args, " "), "", nil
+}
+
 //nolint:funlen,gocognit,cyclop // not sure how to simplify this yet
 func engine(ctx context.Context) (chan<- string, <-chan string, <-chan string) {
 	toEng := make(chan string)
@@ -14,56 +275,172 @@ func engine(ctx context.Context) (chan<- string, <-chan string, <-chan string) {
 
 	var err error
 
-	position := NewPosition()
-	startingFen := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	game := NewGameFromPosition(NewPosition())
+	chess960 := false
+	// frcPosition is set while the position was given by frc or dfrc, whose
+	// castling needs Chess960 notation whatever UCI_Chess960 is set to.
+	frcPosition := false
+	// analysis is the result of the last search, until its move is played.
+	var analysis *searchAnalysis
+	// search is the search in progress, if any.
+	var search *backgroundSearch
+	//nolint:gosec // start positions don't need a secure random source
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// finishSearch sends the best move found by the search in progress.
+	finishSearch := func(result SearchResult) {
+		analysis = newSearchAnalysis(search.position, result, time.Since(search.start))
+		frmEng <- fmt.Sprintf("bestmove %s", search.position.UCIMove(result.Move, search.chess960))
+		search = nil
+	}
 
 	go func() {
 		defer close(frmEng)
-		defer close(toEng)
 		defer close(debug)
+		// the search must be over before frmEng is closed under it.
+		defer func() {
+			if search != nil {
+				search.halt()
+			}
+		}()
 
 		for {
+			var searchDone <-chan SearchResult
+			if search != nil {
+				searchDone = search.done
+			}
+
 			select {
+			case result := <-searchDone:
+				{
+					finishSearch(result)
+				}
 			case <-ctx.Done():
 				{
 					return
 				}
-			case cmd := <-toEng:
+			case cmd, ok := <-toEng:
 				{
+					// the sender closes toEng when it has no more commands.
+					if !ok {
+						if search != nil {
+							finishSearch(search.wait())
+						}
+
+						return
+					}
+
 					words := strings.Split(cmd, " ")
+
+					// the search plays moves on the game's position, so
+					// it is stopped before anything else can touch it.
+					if search != nil && words[0] != "isready" && words[0] != "ponderhit" {
+						finishSearch(search.halt())
+					}
+
 					switch words[0] {
 					case "uci":
 						{
-							go func() {
-								o := handleUci()
-								for x := range o {
-									frmEng <- x
-								}
-							}()
+							for x := range handleUci() {
+								frmEng <- x
+							}
+						}
+					case "isready":
+						{
+							frmEng <- "readyok\n"
+						}
+					case "stop", "ponderhit":
+						{
+							// stop has already ended any search, and
+							// pondering is searched as a normal move.
 						}
 					case "printPosition":
 						{
-							debug <- fmt.Sprintf("info string %s", position.String())
+							debug <- fmt.Sprintf("info string %s", game.Position().String())
+						}
+					case "epd":
+						{
+							lines, epdErr := handleEPD(words[1:])
+							if epdErr != nil {
+								frmEng <- fmt.Sprintf("info string Error running EPD: %s", epdErr)
+
+								break
+							}
+
+							for _, line := range lines {
+								frmEng <- line
+							}
+						}
+					case "savePGN":
+						{
+							if err = handleSavePGN(game, words[1:]); err != nil {
+								frmEng <- fmt.Sprintf("info string Error saving PGN: %s", err)
+
+								break
+							}
+
+							debug <- fmt.Sprintf("info string Saved game to %s", strings.Join(words[1:], " "))
 						}
 					case "position":
 						{
-							if words[1] == "startpos" {
-								err = position.SetPositionFromFen(startingFen)
-								if err != nil {
-									frmEng <- fmt.Sprintf("info string Error setting position: %s", err)
-								}
-							} else {
-								if len(words) < numFenElements+1 {
-									frmEng <- "info string Error setting position: Invalid Fenstring: Missing Fen elements"
+							err = handlePosition(game, words[1:], rng)
+							if err != nil {
+								frmEng <- fmt.Sprintf("info string Error setting position: %s", err)
+
+								break
+							}
 
-									continue
+							if analysis != nil && analysis.annotate(game) {
+								analysis = nil
+							}
+
+							// Chess960 castling can only be told apart from
+							// a king move when written as the king taking
+							// its own rook.
+							frcPosition = len(words) > 1 && (words[1] == "frc" || words[1] == "dfrc")
+						}
+					case "setoption":
+						{
+							name, value, optionErr := parseSetOption(words[1:])
+							if optionErr != nil {
+								frmEng <- fmt.Sprintf("info string Error setting option: %s", optionErr)
+
+								break
+							}
+
+							switch name {
+							case "UCI_Chess960":
+								chess960 = value == "true"
+							default:
+								frmEng <- fmt.Sprintf("info string Unknown option: %s", name)
+							}
+						}
+					case "go":
+						{
+							if len(words) > 1 && words[1] == "perft" {
+								lines, perftErr := handlePerft(game.Position(), words[2:], chess960 || frcPosition)
+								if perftErr != nil {
+									frmEng <- fmt.Sprintf("info string Error running perft: %s", perftErr)
+
+									break
 								}
-								fen := strings.Join(words[1:numFenElements+1], " ")
-								err = position.SetPositionFromFen(fen)
-								if err != nil {
-									frmEng <- fmt.Sprintf("info string Error setting position: %s", err)
+
+								for _, line := range lines {
+									frmEng <- line
 								}
+
+								break
 							}
+
+							// there must always be a bestmove, so the search
+							// goes ahead with whatever limits could be read.
+							position := game.Position()
+							limits, goErr := parseGoLimits(board.Side(position.SideToMove), words[1:])
+							if goErr != nil {
+								frmEng <- fmt.Sprintf("info string Error searching: %s", goErr)
+							}
+
+							search = startSearch(ctx, position, limits, frmEng, chess960 || frcPosition)
 						}
 					default:
 						{
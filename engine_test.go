@@ -41,6 +41,27 @@ func TestEngine(t *testing.T) {
 				})
 			})
 		})
+		Convey("When there are no more commands", func() {
+			Convey("The engine should close its output channels", func() {
+				toEng, frmEng, debug := engine(ctx)
+				toEng <- "isready"
+				close(toEng)
+				So(<-frmEng, ShouldEqual, "readyok\n")
+				_, ok := <-frmEng
+				So(ok, ShouldBeFalse)
+				_, ok = <-debug
+				So(ok, ShouldBeFalse)
+			})
+		})
+		Convey("When given the isready command over the channel", func() {
+			Convey("The engine should reply with readyok", func() {
+				ctx, ctxCancel := context.WithCancel(ctx)
+				toEng, frmEng, _ := engine(ctx)
+				toEng <- "isready"
+				So(<-frmEng, ShouldEqual, "readyok\n")
+				ctxCancel()
+			})
+		})
 		Convey("When given the printPosition command over the channel", func() {
 			Convey("The engine should out put a debug line with the current position", func() {
 				ctx, ctxCancel := context.WithCancel(ctx)
@@ -85,7 +106,7 @@ func TestEngine(t *testing.T) {
 					}
 				})
 				validFenstringsWithMoves := map[string]Position{
-					"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 e2-e4": {
+					"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 moves e2e4": {
 						Board: &board.Board{
 							WhiteKing:    board.NewKing(board.White, board.E1),
 							WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -100,18 +121,67 @@ func TestEngine(t *testing.T) {
 							BlackRooks:   board.NewRooks(board.Black, board.A8, board.H8),
 							BlackPawns:   board.NewPawns(board.Black, board.A7, board.B7, board.C7, board.D7, board.E7, board.F7, board.G7, board.H7),
 						},
-						SideToMove: WHITE,
+						SideToMove: BLACK,
 						CastlingRights: 0 ^ (WhiteKingSideAllowed |
 							WhiteQueenSideAllowed |
 							BlackKingSideAllowed |
 							BlackQueenSideAllowed),
-						EnPassantTarget: 0,
+						EnPassantTarget: board.E3,
+						HalfmoveClock:   0,
+						FullMoveCounter: 1,
+					},
+					"startpos moves e2e4": {
+						Board: &board.Board{
+							WhiteKing:    board.NewKing(board.White, board.E1),
+							WhiteQueens:  board.NewQueens(board.White, board.D1),
+							WhiteBishops: board.NewBishops(board.White, board.C1, board.F1),
+							WhiteKnights: board.NewKnights(board.White, board.B1, board.G1),
+							WhiteRooks:   board.NewRooks(board.White, board.A1, board.H1),
+							WhitePawns:   board.NewPawns(board.White, board.A2, board.B2, board.C2, board.D2, board.E4, board.F2, board.G2, board.H2),
+							BlackKing:    board.NewKing(board.Black, board.E8),
+							BlackQueens:  board.NewQueens(board.Black, board.D8),
+							BlackBishops: board.NewBishops(board.Black, board.C8, board.F8),
+							BlackKnights: board.NewKnights(board.Black, board.B8, board.G8),
+							BlackRooks:   board.NewRooks(board.Black, board.A8, board.H8),
+							BlackPawns:   board.NewPawns(board.Black, board.A7, board.B7, board.C7, board.D7, board.E7, board.F7, board.G7, board.H7),
+						},
+						SideToMove: BLACK,
+						CastlingRights: 0 ^ (WhiteKingSideAllowed |
+							WhiteQueenSideAllowed |
+							BlackKingSideAllowed |
+							BlackQueenSideAllowed),
+						EnPassantTarget: board.E3,
 						HalfmoveClock:   0,
 						FullMoveCounter: 1,
 					},
+					"fen rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 moves e2e4 g8f6 b1c3": {
+						Board: &board.Board{
+							WhiteKing:    board.NewKing(board.White, board.E1),
+							WhiteQueens:  board.NewQueens(board.White, board.D1),
+							WhiteBishops: board.NewBishops(board.White, board.C1, board.F1),
+							WhiteKnights: board.NewKnights(board.White, board.C3, board.G1),
+							WhiteRooks:   board.NewRooks(board.White, board.A1, board.H1),
+							WhitePawns:   board.NewPawns(board.White, board.A2, board.B2, board.C2, board.D2, board.E4, board.F2, board.G2, board.H2),
+							BlackKing:    board.NewKing(board.Black, board.E8),
+							BlackQueens:  board.NewQueens(board.Black, board.D8),
+							BlackBishops: board.NewBishops(board.Black, board.C8, board.F8),
+							BlackKnights: board.NewKnights(board.Black, board.B8, board.F6),
+							BlackRooks:   board.NewRooks(board.Black, board.A8, board.H8),
+							BlackPawns:   board.NewPawns(board.Black, board.A7, board.B7, board.C7, board.D7, board.E7, board.F7, board.G7, board.H7),
+						},
+						SideToMove: BLACK,
+						CastlingRights: 0 ^ (WhiteKingSideAllowed |
+							WhiteQueenSideAllowed |
+							BlackKingSideAllowed |
+							BlackQueenSideAllowed),
+						EnPassantTarget: 0,
+						HalfmoveClock:   2,
+						FullMoveCounter: 2,
+					},
 				}
-				SkipConvey("with moves Should initialise the position and make the relevant moves", func() {
+				Convey("with moves Should initialise the position and make the relevant moves", func() {
 					for fen, finalPosition := range validFenstringsWithMoves {
+						finalPosition.Board.SyncMailbox()
 						ctx, ctxCancel := context.WithCancel(ctx)
 						toEng, _, debug := engine(ctx)
 						toEng <- fmt.Sprintf("position %s", fen)
@@ -125,6 +195,14 @@ func TestEngine(t *testing.T) {
 					}
 				})
 			})
+			Convey("When an illegal move is supplied an error message is returned on the frmEng channel", func() {
+				ctx, ctxCancel := context.WithCancel(ctx)
+				toEng, frmEng, _ := engine(ctx)
+				toEng <- "position startpos moves e2e4 e7e4"
+				So(<-frmEng, ShouldEqual, "info string Error setting position: Invalid move (e7e4) in position "+
+					"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1.\nIllegal move.")
+				ctxCancel()
+			})
 			Convey("When an invalid fen is supplied an error message is returned on the frmEng channel", func() {
 				for fen, errMsg := range invalidFenstrings {
 					ctx, ctxCancel := context.WithCancel(ctx)
@@ -139,6 +217,23 @@ func TestEngine(t *testing.T) {
 				}
 			})
 		})
+		Convey("When given the setoption command", func() {
+			Convey("It should report an option it does not have", func() {
+				ctx, ctxCancel := context.WithCancel(ctx)
+				toEng, frmEng, _ := engine(ctx)
+				toEng <- "setoption name Hash value 16"
+				So(<-frmEng, ShouldEqual, "info string Unknown option: Hash")
+				ctxCancel()
+			})
+			Convey("It should report a malformed command", func() {
+				ctx, ctxCancel := context.WithCancel(ctx)
+				toEng, frmEng, _ := engine(ctx)
+				toEng <- "setoption UCI_Chess960"
+				So(<-frmEng, ShouldEqual, "info string Error setting option: Invalid command "+
+					"(setoption UCI_Chess960). Expected setoption name <id> [value <x>].")
+				ctxCancel()
+			})
+		})
 		Convey("When given an unrecognised command", func() {
 			Convey("It should output notice", func() {
 				ctx, ctxCancel := context.WithCancel(ctx)
@@ -156,3 +251,49 @@ func TestEngine(t *testing.T) {
 		})
 	})
 }
+
+func TestParseSetOption(t *testing.T) {
+	Convey("Given the arguments to setoption", t, func() {
+		Convey("It should split out the name and value", func() {
+			name, value, err := parseSetOption([]string{"name", "UCI_Chess960", "value", "true"})
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "UCI_Chess960")
+			So(value, ShouldEqual, "true")
+		})
+		Convey("Names and values may contain spaces", func() {
+			name, value, err := parseSetOption([]string{"name", "Clear", "Hash"})
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Clear Hash")
+			So(value, ShouldEqual, "")
+			name, value, err = parseSetOption([]string{"name", "Book", "File", "value", "my", "book.bin"})
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "Book File")
+			So(value, ShouldEqual, "my book.bin")
+		})
+	})
+}
+
+func TestHandlePosition(t *testing.T) {
+	Convey("Given a game", t, func() {
+		game := NewGame()
+		So(handlePosition(game, []string{"startpos", "moves", "e2e4"}, nil), ShouldBeNil)
+		annotation, _ := game.Annotate(1)
+		annotation.Comments = []string{"Best by test"}
+		Convey("A position continuing it should play the new moves on it", func() {
+			So(handlePosition(game, []string{"startpos", "moves", "e2e4", "e7e5"}, nil), ShouldBeNil)
+			So(uciMoves(game.Moves()), ShouldResemble, []string{"e2e4", "e7e5"})
+			annotation, _ = game.Annotate(1)
+			So(annotation.Comments, ShouldResemble, []string{"Best by test"})
+		})
+		Convey("Any other position should start it again", func() {
+			So(handlePosition(game, []string{"startpos", "moves", "d2d4"}, nil), ShouldBeNil)
+			So(uciMoves(game.Moves()), ShouldResemble, []string{"d2d4"})
+			annotation, _ = game.Annotate(1)
+			So(annotation.Comments, ShouldBeEmpty)
+		})
+		Convey("An invalid position should leave it as it was", func() {
+			So(handlePosition(game, []string{"startpos", "moves", "e2e4", "e2e4"}, nil), ShouldNotBeNil)
+			So(uciMoves(game.Moves()), ShouldResemble, []string{"e2e4"})
+		})
+	})
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	"github.com/peteches/ChessEngine/notation"
+)
+
+// epdPattern splits an EPD record into its four position fields and its
+// operations.
+//
+//nolint:gochecknoglobals // compiled once
+var epdPattern = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s*(.*)$`)
+
+// EPD is a position in Extended Position Description with its operations.
+type EPD struct {
+	Position *Position
+	// Operations holds the operands of every operation by opcode, strings
+	// without their quotes.
+	Operations map[string][]string
+	// ID and Comment are the id and c0 operations.
+	ID      string
+	Comment string
+	// BestMoves and AvoidMoves are the bm and am operations.
+	BestMoves  []board.Move
+	AvoidMoves []board.Move
+	// CentipawnEval, DirectMate and AnalysisDepth are the ce, dm and acd
+	// operations, nil if they are not given.
+	CentipawnEval *int
+	DirectMate    *int
+	AnalysisDepth *int
+}
+
+// splitOperations splits the operations of an EPD record into opcodes and
+// operands. Each operation ends with a semicolon, operands are separated by
+// spaces unless they are quoted.
+func splitOperations(text string) ([][]string, bool) {
+	operations := [][]string{}
+	operation := []string{}
+	word := strings.Builder{}
+	quoted, inWord := false, false
+
+	endWord := func() {
+		if inWord {
+			operation = append(operation, word.String())
+			word.Reset()
+		}
+
+		inWord = false
+	}
+
+	for _, char := range text {
+		switch {
+		case quoted && char == '"':
+			quoted = false
+		case quoted:
+			word.WriteRune(char)
+		case char == '"':
+			quoted, inWord = true, true
+		case char == ';':
+			endWord()
+
+			if len(operation) > 0 {
+				operations = append(operations, operation)
+			}
+
+			operation = []string{}
+		case char == ' ' || char == '\t':
+			endWord()
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	endWord()
+
+	if len(operation) > 0 {
+		operations = append(operations, operation)
+	}
+
+	return operations, !quoted
+}
+
+// ParseEPD reads an EPD record. The half move clock and full move counter are
+// taken from the hmvc and fmvn operations if they are given.
+func ParseEPD(record string) (*EPD, error) {
+	fail := func(format string, args ...interface{}) *errors.EPDError {
+		return &errors.EPDError{EPD: record, Err: fmt.Sprintf(format, args...)}
+	}
+
+	fields := epdPattern.FindStringSubmatch(record)
+	if fields == nil {
+		return nil, fail("Expected the board, side to move, castling rights and en passant target.")
+	}
+
+	operations, ok := splitOperations(fields[5])
+	if !ok {
+		return nil, fail("Unterminated string.")
+	}
+
+	epd := &EPD{Position: NewPosition(), Operations: map[string][]string{}}
+	for _, operation := range operations {
+		epd.Operations[operation[0]] = operation[1:]
+	}
+
+	clocks := []string{"0", "1"}
+
+	for idx, opcode := range []string{"hmvc", "fmvn"} {
+		if operands, ok := epd.Operations[opcode]; ok && len(operands) == 1 {
+			clocks[idx] = operands[0]
+		}
+	}
+
+	fen := strings.Join(fields[1:5], " ") + " " + strings.Join(clocks, " ")
+	if err := epd.Position.SetPositionFromFen(fen); err != nil {
+		return nil, fail("%s", err)
+	}
+
+	epd.ID = strings.Join(epd.Operations["id"], " ")
+	epd.Comment = strings.Join(epd.Operations["c0"], " ")
+
+	for opcode, moves := range map[string]*[]board.Move{"bm": &epd.BestMoves, "am": &epd.AvoidMoves} {
+		for _, san := range epd.Operations[opcode] {
+			move, err := notation.ParseSAN(epd.Position, san)
+			if err != nil {
+				return nil, fail("Invalid move (%s) for %s. %s", san, opcode, err.Err)
+			}
+
+			*moves = append(*moves, move)
+		}
+	}
+
+	for opcode, value := range map[string]**int{"ce": &epd.CentipawnEval, "dm": &epd.DirectMate, "acd": &epd.AnalysisDepth} {
+		operands, ok := epd.Operations[opcode]
+		if !ok {
+			continue
+		}
+
+		number, err := strconv.Atoi(strings.Join(operands, " "))
+		if err != nil {
+			return nil, fail("Expected a number for %s.", opcode)
+		}
+
+		*value = &number
+	}
+
+	return epd, nil
+}
+
+// EPDResult is how a search did on an EPD test position.
+type EPDResult struct {
+	ID     string
+	Search SearchResult
+	// SAN is the move found.
+	SAN string
+	// Tested is false if the position has no bm, am or dm operation to
+	// check the search against.
+	Tested bool
+	Passed bool
+}
+
+// RunEPD searches the EPD's position within limits and checks the move found
+// against its bm and am operations and the mate found against dm.
+func RunEPD(epd *EPD, limits SearchLimits) EPDResult {
+	found := Search(epd.Position, limits, nil)
+	result := EPDResult{ID: epd.ID, Search: found, Passed: true}
+	// the search only returns legal moves.
+	result.SAN, _ = notation.SAN(epd.Position, found.Move)
+
+	contains := func(moves []board.Move) bool {
+		for _, move := range moves {
+			if move == found.Move {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(epd.BestMoves) > 0 {
+		result.Tested = true
+		result.Passed = result.Passed && contains(epd.BestMoves)
+	}
+
+	if len(epd.AvoidMoves) > 0 {
+		result.Tested = true
+		result.Passed = result.Passed && !contains(epd.AvoidMoves)
+	}
+
+	if epd.DirectMate != nil {
+		result.Tested = true
+		mate := found.Mate()
+		result.Passed = result.Passed && mate > 0 && mate <= *epd.DirectMate
+	}
+
+	result.Passed = result.Passed && result.Tested
+
+	return result
+}
+
+// epdExpectation describes what an EPD test position expects of a search.
+func epdExpectation(epd *EPD) string {
+	expected := []string{}
+
+	for _, opcode := range []string{"bm", "am", "dm"} {
+		if operands, ok := epd.Operations[opcode]; ok {
+			expected = append(expected, opcode+" "+strings.Join(operands, " "))
+		}
+	}
+
+	return strings.Join(expected, "; ")
+}
+
+// runEPDSuite runs every EPD record read from reader, returning a line for
+// each and a final line with the score. Malformed records are reported and
+// skipped.
+func runEPDSuite(reader io.Reader, limits SearchLimits) []string {
+	lines := []string{}
+	scanner := bufio.NewScanner(reader)
+	passed, tested := 0, 0
+
+	for number := 1; scanner.Scan(); number++ {
+		record := strings.TrimSpace(scanner.Text())
+		if record == "" {
+			continue
+		}
+
+		epd, err := ParseEPD(record)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("info string Error on line %d: %s", number, err))
+
+			continue
+		}
+
+		if epd.ID == "" {
+			epd.ID = fmt.Sprintf("line %d", number)
+		}
+
+		result := RunEPD(epd, limits)
+		verdict := "fail"
+
+		switch {
+		case !result.Tested:
+			verdict = "untested"
+		case result.Passed:
+			verdict = "pass"
+			passed++
+		}
+
+		if result.Tested {
+			tested++
+		}
+
+		lines = append(lines, fmt.Sprintf("info string %s %s %s (%s)",
+			result.ID, verdict, result.SAN, epdExpectation(epd)))
+	}
+
+	if err := scanner.Err(); err != nil {
+		lines = append(lines, fmt.Sprintf("info string Error reading EPD: %s", err))
+	}
+
+	return append(lines, fmt.Sprintf("info string Passed %d of %d", passed, tested))
+}
+
+// handleEPD runs an EPD test suite from the arguments to
+//
+//	epd <file> [depth <plies>] [movetime <milliseconds>]
+func handleEPD(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, &errors.InvalidCommandError{
+			Cmd: "epd",
+			Err: "Expected epd <file> [depth <plies>] [movetime <milliseconds>].",
+		}
+	}
+
+	limits, err := parseSearchLimits("epd "+args[0], args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return runEPDSuite(file, limits), nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const wac001 = `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`
+
+//nolint:funlen // Convey testing is verbose
+func TestEPD(t *testing.T) {
+	Convey("Given ParseEPD()", t, func() {
+		Convey("It should read the position and operations", func() {
+			epd, err := ParseEPD(`r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - ` +
+				`bm Bb5 Bc4; am Nxe5; id "ECM.001"; c0 "Ruy Lopez; or Italian"; ce +35; acd 12; hmvc 2; fmvn 3;`)
+			So(err, ShouldBeNil)
+			So(epd.Position.String(), ShouldEqual, "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3")
+			So(epd.ID, ShouldEqual, "ECM.001")
+			So(epd.Comment, ShouldEqual, "Ruy Lopez; or Italian")
+			So(uciMoves(epd.BestMoves), ShouldResemble, []string{"f1b5", "f1c4"})
+			So(uciMoves(epd.AvoidMoves), ShouldResemble, []string{"f3e5"})
+			So(*epd.CentipawnEval, ShouldEqual, 35)
+			So(*epd.AnalysisDepth, ShouldEqual, 12)
+			So(epd.DirectMate, ShouldBeNil)
+			So(epd.Operations["bm"], ShouldResemble, []string{"Bb5", "Bc4"})
+		})
+		Convey("It should default the clocks and accept no operations", func() {
+			epd, err := ParseEPD("4k3/8/8/8/8/8/8/4K3 b - -")
+			So(err, ShouldBeNil)
+			So(epd.Position.String(), ShouldEqual, "4k3/8/8/8/8/8/8/4K3 b - - 0 1")
+			So(epd.Operations, ShouldBeEmpty)
+		})
+		Convey("It should reject a malformed record", func() {
+			for record, want := range map[string]string{
+				"4k3/8/8/8/8/8/8/4K3 w":                     "Expected the board, side to move, castling rights and en passant target.",
+				"4k3/8/8/8/8/8/8/4K3 w - - id \"unfinished": "Unterminated string.",
+				"4k3/8/8/8/8/8/8/4K3 w - - bm Kf3;":         "Invalid move (Kf3) for bm. Illegal move.",
+				"4k3/8/8/8/8/8/8/4K3 w - - dm two;":         "Expected a number for dm.",
+				"8/8/8/8/8/8/8/4K3 w - - id \"x\";": "Invalid number of black kings (0) in Fen " +
+					"(8/8/8/8/8/8/8/4K3 w - - 0 1). Each side must have exactly one king.",
+			} {
+				_, err := ParseEPD(record)
+				So(err, ShouldResemble, &errors.EPDError{EPD: record, Err: want})
+			}
+		})
+	})
+	Convey("Given RunEPD()", t, func() {
+		limits := SearchLimits{Depth: 3}
+		Convey("It should pass when the best move is found", func() {
+			epd, err := ParseEPD(wac001)
+			So(err, ShouldBeNil)
+			result := RunEPD(epd, limits)
+			So(result, ShouldResemble, EPDResult{
+				ID: "WAC.001", Search: result.Search, SAN: "Qg6", Tested: true, Passed: true,
+			})
+		})
+		Convey("It should fail when a move to avoid is played", func() {
+			epd, err := ParseEPD(`4k3/8/8/3q4/8/8/3R4/4K3 w - - am Rxd5; id "bait";`)
+			So(err, ShouldBeNil)
+			result := RunEPD(epd, limits)
+			So(result.SAN, ShouldEqual, "Rxd5")
+			So(result.Passed, ShouldBeFalse)
+		})
+		Convey("It should check direct mates", func() {
+			epd, err := ParseEPD(`k7/8/2K5/8/8/8/8/7R w - - dm 2;`)
+			So(err, ShouldBeNil)
+			So(RunEPD(epd, limits).Passed, ShouldBeTrue)
+			So(RunEPD(epd, SearchLimits{Depth: 2}).Passed, ShouldBeFalse)
+		})
+		Convey("It should not pass a position with nothing to check", func() {
+			epd, err := ParseEPD(`4k3/8/8/8/8/8/8/4K3 w - - id "empty";`)
+			So(err, ShouldBeNil)
+			result := RunEPD(epd, limits)
+			So(result.Tested, ShouldBeFalse)
+			So(result.Passed, ShouldBeFalse)
+		})
+	})
+	Convey("Given runEPDSuite()", t, func() {
+		suite := strings.Join([]string{
+			wac001,
+			"",
+			`4k3/8/8/3q4/8/8/3R4/4K3 w - - am Rxd5;`,
+			`4k3/8/8/8/8/8/8/4K3 w - - bm Ke9;`,
+		}, "\n")
+		So(runEPDSuite(strings.NewReader(suite), SearchLimits{Depth: 3}), ShouldResemble, []string{
+			"info string WAC.001 pass Qg6 (bm Qg6)",
+			"info string line 3 fail Rxd5 (am Rxd5)",
+			"info string Error on line 4: Invalid EPD (4k3/8/8/8/8/8/8/4K3 w - - bm Ke9;). " +
+				"Invalid move (Ke9) for bm. Moves should be in Standard Algebraic Notation, e.g. Nf3 or exd5.",
+			"info string Passed 1 of 2",
+		})
+	})
+	Convey("Given an engine", t, func() {
+		ctx, ctxCancel := context.WithCancel(context.Background())
+		toEng, frmEng, _ := engine(ctx)
+		Convey("epd should run a suite from a file", func() {
+			file := filepath.Join(t.TempDir(), "wac.epd")
+			So(os.WriteFile(file, []byte(wac001+"\n"), 0o600), ShouldBeNil)
+			toEng <- "epd " + file + " depth 3"
+			So(<-frmEng, ShouldEqual, "info string WAC.001 pass Qg6 (bm Qg6)")
+			So(<-frmEng, ShouldEqual, "info string Passed 1 of 1")
+		})
+		Convey("epd should report bad arguments", func() {
+			toEng <- "epd"
+			So(<-frmEng, ShouldEqual, "info string Error running EPD: Invalid command (epd). "+
+				"Expected epd <file> [depth <plies>] [movetime <milliseconds>].")
+			toEng <- "epd suite.epd depth"
+			So(<-frmEng, ShouldEqual, "info string Error running EPD: Invalid command (epd suite.epd depth). "+
+				"Expected depth <plies> or movetime <milliseconds>.")
+		})
+		Reset(ctxCancel)
+	})
+}
@@ -29,7 +29,7 @@ type CastlingRightsError struct {
 
 func (e *CastlingRightsError) Error() string {
 	return fmt.Sprintf("Invalid castling rights found (%c) in Fen (%s). "+
-		"Should only contain characters 'kqKQ-'", e.ErrChar, e.Fen)
+		"Should only contain characters 'kqKQ-' or rook files 'a-hA-H'", e.ErrChar, e.Fen)
 }
 
 type EnPassantTargetError struct {
@@ -40,7 +40,7 @@ type EnPassantTargetError struct {
 func (e *EnPassantTargetError) Error() string {
 	return fmt.Sprintf("Invalid en passant target square (%s) in Fen (%s). "+
 		"Should be one of "+
-		"[A3,B3,C3,D3,E3,F3,G3,H3,A6,B6,C6,D6,E6,F6,G6,H6]",
+		"[a3,b3,c3,d3,e3,f3,g3,h3,a6,b6,c6,d6,e6,f6,g6,h6]",
 		e.ErrTarget, e.Fen)
 }
 
@@ -89,3 +89,109 @@ type InvalidFenstringError struct {
 func (e *InvalidFenstringError) Error() string {
 	return fmt.Sprintf("Invalid Fenstring: %s", e.Err)
 }
+
+type InvalidCommandError struct {
+	Cmd string
+	Err string
+}
+
+func (e *InvalidCommandError) Error() string {
+	return fmt.Sprintf("Invalid command (%s). %s", e.Cmd, e.Err)
+}
+
+type PlyError struct {
+	Ply   int
+	Plies int
+}
+
+func (e *PlyError) Error() string {
+	return fmt.Sprintf("Invalid ply (%d). Must be between 0 and %d.", e.Ply, e.Plies)
+}
+
+type KingCountError struct {
+	Fen   string
+	Side  string
+	Count int
+}
+
+func (e *KingCountError) Error() string {
+	return fmt.Sprintf("Invalid number of %s kings (%d) in Fen (%s). "+
+		"Each side must have exactly one king.", e.Side, e.Count, e.Fen)
+}
+
+type PawnRankError struct {
+	Fen    string
+	Square string
+}
+
+func (e *PawnRankError) Error() string {
+	return fmt.Sprintf("Invalid pawn on %s in Fen (%s). "+
+		"Pawns cannot be on the first or eighth rank.", e.Square, e.Fen)
+}
+
+type EnPassantError struct {
+	Fen    string
+	Square string
+	Err    string
+}
+
+func (e *EnPassantError) Error() string {
+	return fmt.Sprintf("Invalid en passant target (%s) in Fen (%s). %s", e.Square, e.Fen, e.Err)
+}
+
+type PieceCountError struct {
+	Fen  string
+	Side string
+	Err  string
+}
+
+func (e *PieceCountError) Error() string {
+	return fmt.Sprintf("Too many %s pieces in Fen (%s). %s", e.Side, e.Fen, e.Err)
+}
+
+type CheckError struct {
+	Fen string
+	Err string
+}
+
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("Illegal check in Fen (%s). %s", e.Fen, e.Err)
+}
+
+type CastlingPiecesError struct {
+	Fen   string
+	Right rune
+}
+
+func (e *CastlingPiecesError) Error() string {
+	return fmt.Sprintf("Invalid castling right (%c) in Fen (%s). "+
+		"The king and rook must be on their starting squares.", e.Right, e.Fen)
+}
+
+type Chess960IndexError struct {
+	Index string
+}
+
+func (e *Chess960IndexError) Error() string {
+	return fmt.Sprintf("Invalid Chess960 position (%s). "+
+		"Must be a number between 0 and 959 or random.", e.Index)
+}
+
+type PGNError struct {
+	Line   int
+	Column int
+	Err    string
+}
+
+func (e *PGNError) Error() string {
+	return fmt.Sprintf("Invalid PGN at line %d, column %d. %s", e.Line, e.Column, e.Err)
+}
+
+type EPDError struct {
+	EPD string
+	Err string
+}
+
+func (e *EPDError) Error() string {
+	return fmt.Sprintf("Invalid EPD (%s). %s", e.EPD, e.Err)
+}
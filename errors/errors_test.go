@@ -52,7 +52,7 @@ func TestErrors(t *testing.T) {
 			ErrChar: 'z',
 		}
 		ErrMsg := fmt.Sprintf("Invalid castling rights found (%c) in Fen (%s). "+
-			"Should only contain characters 'kqKQ-'", Err.ErrChar, Err.Fen)
+			"Should only contain characters 'kqKQ-' or rook files 'a-hA-H'", Err.ErrChar, Err.Fen)
 		Convey("It should implement the Error interface", func() {
 			So(Err, ShouldImplement, (*error)(nil))
 			So(Err.Error(), ShouldEqual, ErrMsg)
@@ -70,7 +70,7 @@ func TestErrors(t *testing.T) {
 			ErrTarget: "w",
 		}
 		ErrMsg := fmt.Sprintf("Invalid en passant target square (%s) in Fen (%s). "+
-			"Should be one of [A3,B3,C3,D3,E3,F3,G3,H3,A6,B6,C6,D6,E6,F6,G6,H6]", Err.ErrTarget, Err.Fen)
+			"Should be one of [a3,b3,c3,d3,e3,f3,g3,h3,a6,b6,c6,d6,e6,f6,g6,h6]", Err.ErrTarget, Err.Fen)
 		Convey("It should implement the Error interface", func() {
 			So(Err, ShouldImplement, (*error)(nil))
 			So(Err.Error(), ShouldEqual, ErrMsg)
@@ -168,4 +168,204 @@ func TestErrors(t *testing.T) {
 			So(Err.Err, ShouldHaveSameTypeAs, "h")
 		})
 	})
+	Convey("Given an InvalidCommandError", t, func() {
+		Err := &errors.InvalidCommandError{
+			Cmd: "go perft x",
+			Err: "Depth must be a positive number.",
+		}
+		ErrMsg := "Invalid command (go perft x). Depth must be a positive number."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Cmd attribute", func() {
+			So(Err.Cmd, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have an Err attribute", func() {
+			So(Err.Err, ShouldHaveSameTypeAs, "h")
+		})
+	})
+	Convey("Given a PlyError", t, func() {
+		Err := &errors.PlyError{
+			Ply:   7,
+			Plies: 4,
+		}
+		ErrMsg := "Invalid ply (7). Must be between 0 and 4."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Ply attribute", func() {
+			So(Err.Ply, ShouldHaveSameTypeAs, 0)
+		})
+		Convey("It should have a Plies attribute", func() {
+			So(Err.Plies, ShouldHaveSameTypeAs, 0)
+		})
+	})
+	Convey("Given a KingCountError", t, func() {
+		Err := &errors.KingCountError{
+			Fen:   "8/8/8/8/8/8/8/4K3 w - - 0 1",
+			Side:  "black",
+			Count: 0,
+		}
+		ErrMsg := "Invalid number of black kings (0) in Fen (8/8/8/8/8/8/8/4K3 w - - 0 1). Each side must have exactly one king."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Fen attribute", func() {
+			So(Err.Fen, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have a Side attribute", func() {
+			So(Err.Side, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have a Count attribute", func() {
+			So(Err.Count, ShouldHaveSameTypeAs, 0)
+		})
+	})
+	Convey("Given a PawnRankError", t, func() {
+		Err := &errors.PawnRankError{
+			Fen:    "4k2P/8/8/8/8/8/8/4K3 w - - 0 1",
+			Square: "h8",
+		}
+		ErrMsg := "Invalid pawn on h8 in Fen (4k2P/8/8/8/8/8/8/4K3 w - - 0 1). Pawns cannot be on the first or eighth rank."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Fen attribute", func() {
+			So(Err.Fen, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have a Square attribute", func() {
+			So(Err.Square, ShouldHaveSameTypeAs, "")
+		})
+	})
+	Convey("Given an EnPassantError", t, func() {
+		Err := &errors.EnPassantError{
+			Fen:    "4k3/8/8/8/8/8/8/4K3 w - e6 0 1",
+			Square: "e6",
+			Err:    "The pawn which just moved two squares must be in front of the en passant target.",
+		}
+		ErrMsg := "Invalid en passant target (e6) in Fen (4k3/8/8/8/8/8/8/4K3 w - e6 0 1). " +
+			"The pawn which just moved two squares must be in front of the en passant target."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Fen attribute", func() {
+			So(Err.Fen, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have a Square attribute", func() {
+			So(Err.Square, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have an Err attribute", func() {
+			So(Err.Err, ShouldHaveSameTypeAs, "")
+		})
+	})
+	Convey("Given a PieceCountError", t, func() {
+		Err := &errors.PieceCountError{
+			Fen:  "4k3/8/8/8/8/8/PPPPPPPP/3PK3 w - - 0 1",
+			Side: "white",
+			Err:  "A side cannot have more than 8 pawns.",
+		}
+		ErrMsg := "Too many white pieces in Fen (4k3/8/8/8/8/8/PPPPPPPP/3PK3 w - - 0 1). A side cannot have more than 8 pawns."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Fen attribute", func() {
+			So(Err.Fen, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have a Side attribute", func() {
+			So(Err.Side, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have an Err attribute", func() {
+			So(Err.Err, ShouldHaveSameTypeAs, "")
+		})
+	})
+	Convey("Given a CheckError", t, func() {
+		Err := &errors.CheckError{
+			Fen: "4k3/8/8/8/8/8/8/4KR2 b - - 0 1",
+			Err: "The side not to move cannot be in check.",
+		}
+		ErrMsg := "Illegal check in Fen (4k3/8/8/8/8/8/8/4KR2 b - - 0 1). The side not to move cannot be in check."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Fen attribute", func() {
+			So(Err.Fen, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have an Err attribute", func() {
+			So(Err.Err, ShouldHaveSameTypeAs, "")
+		})
+	})
+	Convey("Given a CastlingPiecesError", t, func() {
+		Err := &errors.CastlingPiecesError{
+			Fen:   "4k3/8/8/8/8/8/8/4K3 w K - 0 1",
+			Right: 'K',
+		}
+		ErrMsg := "Invalid castling right (K) in Fen (4k3/8/8/8/8/8/8/4K3 w K - 0 1). The king and rook must be on their starting squares."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Fen attribute", func() {
+			So(Err.Fen, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have a Right attribute", func() {
+			So(Err.Right, ShouldHaveSameTypeAs, 'K')
+		})
+	})
+	Convey("Given a Chess960IndexError", t, func() {
+		Err := &errors.Chess960IndexError{
+			Index: "960",
+		}
+		ErrMsg := "Invalid Chess960 position (960). Must be a number between 0 and 959 or random."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have an Index attribute", func() {
+			So(Err.Index, ShouldHaveSameTypeAs, "")
+		})
+	})
+	Convey("Given a PGNError", t, func() {
+		Err := &errors.PGNError{
+			Line:   12,
+			Column: 5,
+			Err:    "Unterminated variation.",
+		}
+		ErrMsg := "Invalid PGN at line 12, column 5. Unterminated variation."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have a Line attribute", func() {
+			So(Err.Line, ShouldHaveSameTypeAs, 0)
+		})
+		Convey("It should have a Column attribute", func() {
+			So(Err.Column, ShouldHaveSameTypeAs, 0)
+		})
+		Convey("It should have an Err attribute", func() {
+			So(Err.Err, ShouldHaveSameTypeAs, "")
+		})
+	})
+	Convey("Given an EPDError", t, func() {
+		Err := &errors.EPDError{
+			EPD: "8/8/8/8/8/8/8/8 w - - bm;",
+			Err: "Expected operands for bm.",
+		}
+		ErrMsg := "Invalid EPD (8/8/8/8/8/8/8/8 w - - bm;). Expected operands for bm."
+		Convey("It should implement the Error interface", func() {
+			So(Err, ShouldImplement, (*error)(nil))
+			So(Err.Error(), ShouldEqual, ErrMsg)
+		})
+		Convey("It should have an EPD attribute", func() {
+			So(Err.EPD, ShouldHaveSameTypeAs, "")
+		})
+		Convey("It should have an Err attribute", func() {
+			So(Err.Err, ShouldHaveSameTypeAs, "")
+		})
+	})
 }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"time"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+)
+
+// Tag is a PGN style tag pair, such as Event or White.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// sevenTagRoster are the tags every PGN game has, in the order they are
+// written.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var sevenTagRoster = []Tag{
+	{Name: "Event", Value: "?"},
+	{Name: "Site", Value: "?"},
+	{Name: "Date", Value: "????.??.??"},
+	{Name: "Round", Value: "?"},
+	{Name: "White", Value: "?"},
+	{Name: "Black", Value: "?"},
+	{Name: "Result", Value: "*"},
+}
+
+// Analysis is an engine's assessment after a move it chose.
+type Analysis struct {
+	// Score is in centipawns for the side which made the move.
+	Score int
+	// Mate, if not 0, is the number of moves to mate, negative when the side
+	// which made the move is being mated. It takes the place of Score.
+	Mate  int
+	Depth int
+	Time  time.Duration
+}
+
+// Annotation is the commentary on a move: the engine analysis, comments and
+// Numeric Annotation Glyphs (NAGs) following it and the variations played
+// instead of it.
+type Annotation struct {
+	Analysis   *Analysis
+	Comments   []string
+	NAGs       []int
+	Variations []Line
+}
+
+// Line is a sequence of moves, such as a variation, with their annotations.
+// Annotations[0] holds the comments before the first move and Annotations[i]
+// is the annotation of Moves[i-1].
+type Line struct {
+	Moves       []board.Move
+	Annotations []Annotation
+}
+
+// Game is a game played from a starting position. It keeps every move made
+// so moves can be taken back and replayed, and the position at any ply can be
+// revisited.
+type Game struct {
+	start    *Position
+	position *Position
+	// moves holds every move in the game, including those taken back
+	// which can still be replayed.
+	moves []board.Move
+	// annotations are indexed by ply like Line.Annotations.
+	annotations []Annotation
+	ply         int
+	tags        []Tag
+}
+
+// NewGame returns a game from the standard starting position.
+func NewGame() *Game {
+	position := NewPosition()
+	// the starting position is always valid.
+	_ = position.SetPositionFromFen(startingFen)
+
+	return NewGameFromPosition(position)
+}
+
+// NewGameFromPosition returns a game starting from a copy of position.
+func NewGameFromPosition(position *Position) *Game {
+	game := &Game{
+		start:       position.Clone(),
+		position:    position.Clone(),
+		annotations: []Annotation{{}},
+		tags:        append([]Tag{}, sevenTagRoster...),
+	}
+
+	if fen := position.String(); fen != startingFen {
+		game.SetTag("SetUp", "1")
+		game.SetTag("FEN", fen)
+	}
+
+	return game
+}
+
+// SetPositionFromFen starts the game again from fen. The game is left as it
+// was if fen is invalid.
+func (g *Game) SetPositionFromFen(fen string) error {
+	position := NewPosition()
+	if err := position.SetPositionFromFen(fen); err != nil {
+		return err
+	}
+
+	*g = *NewGameFromPosition(position)
+
+	return nil
+}
+
+// Position returns the current position. It must not be changed directly,
+// use the Game's methods to move through the game.
+func (g *Game) Position() *Position {
+	return g.position
+}
+
+// StartPosition returns a copy of the position the game started from.
+func (g *Game) StartPosition() *Position {
+	return g.start.Clone()
+}
+
+// MakeMove plays move in the current position. Any moves which were taken
+// back are discarded, along with their annotations.
+func (g *Game) MakeMove(move board.Move) *errors.MoveError {
+	if err := g.position.MakeMove(move); err != nil {
+		return err
+	}
+
+	// the position records the move with its flags set.
+	played := g.position.history[len(g.position.history)-1].move
+	g.moves = append(g.moves[:g.ply], played)
+	g.annotations = append(g.annotations[:g.ply+1], Annotation{})
+	g.ply++
+
+	return nil
+}
+
+// Undo takes back the last move, returning false if there is none.
+func (g *Game) Undo() bool {
+	if g.ply == 0 {
+		return false
+	}
+
+	g.position.UnmakeMove()
+	g.ply--
+
+	return true
+}
+
+// Redo replays the last move taken back, returning false if there is none.
+func (g *Game) Redo() bool {
+	if g.ply == len(g.moves) {
+		return false
+	}
+
+	g.position.play(g.moves[g.ply])
+	g.ply++
+
+	return true
+}
+
+// GoTo moves through the game to ply, 0 being the starting position.
+func (g *Game) GoTo(ply int) *errors.PlyError {
+	if ply < 0 || ply > len(g.moves) {
+		return &errors.PlyError{Ply: ply, Plies: len(g.moves)}
+	}
+
+	for g.ply > ply {
+		g.Undo()
+	}
+
+	for g.ply < ply {
+		g.Redo()
+	}
+
+	return nil
+}
+
+// Ply returns the number of moves played to reach the current position.
+func (g *Game) Ply() int {
+	return g.ply
+}
+
+// Len returns the number of moves in the game, including any taken back.
+func (g *Game) Len() int {
+	return len(g.moves)
+}
+
+// Moves returns the moves played to reach the current position.
+func (g *Game) Moves() []board.Move {
+	return append([]board.Move{}, g.moves[:g.ply]...)
+}
+
+// Keys returns the Zobrist key of every position from the start of the game
+// to the current one.
+func (g *Game) Keys() []uint64 {
+	keys := make([]uint64, 0, g.ply+1)
+
+	for _, record := range g.position.history {
+		keys = append(keys, record.hash)
+	}
+
+	return append(keys, g.position.Hash())
+}
+
+// RepetitionCount returns how many times the current position has occurred
+// in the game.
+func (g *Game) RepetitionCount() int {
+	return g.position.RepetitionCount()
+}
+
+// Outcome returns whether the game is over in the current position and why.
+func (g *Game) Outcome() Outcome {
+	return g.position.Outcome()
+}
+
+// Annotate returns the annotation of the move which reached ply, for changing
+// in place. Ply 0 holds the comments before the first move.
+func (g *Game) Annotate(ply int) (*Annotation, *errors.PlyError) {
+	if ply < 0 || ply > len(g.moves) {
+		return nil, &errors.PlyError{Ply: ply, Plies: len(g.moves)}
+	}
+
+	return &g.annotations[ply], nil
+}
+
+// Tag returns the value of the named tag and whether it is set.
+func (g *Game) Tag(name string) (string, bool) {
+	for _, tag := range g.tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// SetTag sets the named tag, adding it after the others if it is new.
+func (g *Game) SetTag(name, value string) {
+	for idx := range g.tags {
+		if g.tags[idx].Name == name {
+			g.tags[idx].Value = value
+
+			return
+		}
+	}
+
+	g.tags = append(g.tags, Tag{Name: name, Value: value})
+}
+
+// Tags returns the game's tags, the seven tag roster first.
+func (g *Game) Tags() []Tag {
+	return append([]Tag{}, g.tags...)
+}
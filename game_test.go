@@ -0,0 +1,160 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestGame(t *testing.T) {
+	Convey("Given a new Game", t, func() {
+		game := NewGame()
+		playMoves := func(moves ...string) {
+			for _, move := range moves {
+				So(game.MakeMove(mustParseMove(move)), ShouldBeNil)
+			}
+		}
+		fenAfter := func(moves ...string) string {
+			position := NewPosition()
+			So(position.SetPositionFromFen(startingFen), ShouldBeNil)
+			for _, move := range moves {
+				So(position.MakeMove(mustParseMove(move)), ShouldBeNil)
+			}
+
+			return position.String()
+		}
+		Convey("It should start from the starting position", func() {
+			So(game.Position().String(), ShouldEqual, startingFen)
+			So(game.StartPosition().String(), ShouldEqual, startingFen)
+			So(game.Ply(), ShouldEqual, 0)
+			So(game.Len(), ShouldEqual, 0)
+			So(game.Moves(), ShouldBeEmpty)
+		})
+		Convey("It should have the seven tag roster and no FEN tag", func() {
+			So(game.Tags(), ShouldResemble, sevenTagRoster)
+			_, ok := game.Tag("FEN")
+			So(ok, ShouldBeFalse)
+		})
+		Convey("MakeMove() should record the move with its flags", func() {
+			playMoves("e2e4", "e7e5", "g1f3")
+			So(game.Ply(), ShouldEqual, 3)
+			So(game.Moves(), ShouldResemble, []board.Move{
+				board.NewMove(board.E2, board.E4, board.NoPieceType, board.DoublePush),
+				board.NewMove(board.E7, board.E5, board.NoPieceType, board.DoublePush),
+				board.NewMove(board.G1, board.F3, board.NoPieceType),
+			})
+			So(game.Position().String(), ShouldEqual, fenAfter("e2e4", "e7e5", "g1f3"))
+		})
+		Convey("MakeMove() should reject an illegal move and leave the game alone", func() {
+			So(game.MakeMove(mustParseMove("e2e5")), ShouldHaveSameTypeAs, &errors.MoveError{})
+			So(game.Ply(), ShouldEqual, 0)
+			So(game.Position().String(), ShouldEqual, startingFen)
+		})
+		Convey("Undo() and Redo() should move back and forward through the moves", func() {
+			So(game.Undo(), ShouldBeFalse)
+			playMoves("e2e4", "e7e5")
+			So(game.Undo(), ShouldBeTrue)
+			So(game.Position().String(), ShouldEqual, fenAfter("e2e4"))
+			So(game.Undo(), ShouldBeTrue)
+			So(game.Position().String(), ShouldEqual, startingFen)
+			So(game.Len(), ShouldEqual, 2)
+			So(game.Redo(), ShouldBeTrue)
+			So(game.Redo(), ShouldBeTrue)
+			So(game.Redo(), ShouldBeFalse)
+			So(game.Position().String(), ShouldEqual, fenAfter("e2e4", "e7e5"))
+		})
+		Convey("A new move after Undo() should replace the moves taken back", func() {
+			playMoves("e2e4", "e7e5", "g1f3")
+			So(game.Undo(), ShouldBeTrue)
+			So(game.Undo(), ShouldBeTrue)
+			playMoves("c7c5")
+			So(game.Len(), ShouldEqual, 2)
+			So(game.Redo(), ShouldBeFalse)
+			So(game.Position().String(), ShouldEqual, fenAfter("e2e4", "c7c5"))
+		})
+		Convey("GoTo() should go to any ply", func() {
+			moves := []string{"d2d4", "d7d5", "c2c4", "d5c4", "e2e3"}
+			playMoves(moves...)
+			for _, ply := range []int{2, 0, 5, 3, 4} {
+				So(game.GoTo(ply), ShouldBeNil)
+				So(game.Ply(), ShouldEqual, ply)
+				So(game.Position().String(), ShouldEqual, fenAfter(moves[:ply]...))
+			}
+			Convey("But not beyond the game", func() {
+				So(game.GoTo(6), ShouldResemble, &errors.PlyError{Ply: 6, Plies: 5})
+				So(game.GoTo(-1), ShouldResemble, &errors.PlyError{Ply: -1, Plies: 5})
+				So(game.Ply(), ShouldEqual, 4)
+			})
+		})
+		Convey("Keys() should hold the hash of every position reached", func() {
+			playMoves("g1f3", "g8f6", "f3g1", "f6g8")
+			keys := game.Keys()
+			So(len(keys), ShouldEqual, 5)
+			So(keys[0], ShouldEqual, keys[4])
+			So(keys[4], ShouldEqual, game.Position().Hash())
+			So(game.RepetitionCount(), ShouldEqual, 2)
+			So(game.Undo(), ShouldBeTrue)
+			So(game.Keys(), ShouldResemble, keys[:4])
+		})
+		Convey("Outcome() should report repetitions made in the game", func() {
+			for idx := 0; idx < 2; idx++ {
+				playMoves("g1f3", "g8f6", "f3g1", "f6g8")
+			}
+			So(game.Outcome(), ShouldResemble, Outcome{Result: Draw, Reason: ThreefoldRepetition})
+			So(game.Undo(), ShouldBeTrue)
+			So(game.Outcome().Reason, ShouldEqual, Ongoing)
+		})
+		Convey("SetTag() should update a tag or add it after the others", func() {
+			game.SetTag("White", "Fischer, Robert J.")
+			game.SetTag("Annotator", "peteches")
+			white, ok := game.Tag("White")
+			So(ok, ShouldBeTrue)
+			So(white, ShouldEqual, "Fischer, Robert J.")
+			tags := game.Tags()
+			So(len(tags), ShouldEqual, len(sevenTagRoster)+1)
+			So(tags[4], ShouldResemble, Tag{Name: "White", Value: "Fischer, Robert J."})
+			So(tags[7], ShouldResemble, Tag{Name: "Annotator", Value: "peteches"})
+		})
+		Convey("SetPositionFromFen() should start a new game from that position", func() {
+			fen := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+			playMoves("e2e4")
+			So(game.SetPositionFromFen(fen), ShouldBeNil)
+			So(game.Position().String(), ShouldEqual, fen)
+			So(game.Len(), ShouldEqual, 0)
+			setUp, _ := game.Tag("SetUp")
+			So(setUp, ShouldEqual, "1")
+			fenTag, _ := game.Tag("FEN")
+			So(fenTag, ShouldEqual, fen)
+			Convey("But leave the game alone if the fen is invalid", func() {
+				So(game.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R x KQkq - 0 1"), ShouldNotBeNil)
+				So(game.Position().String(), ShouldEqual, fen)
+			})
+		})
+		Convey("Annotate() should hold the annotation of each move", func() {
+			playMoves("e2e4", "e7e5")
+			annotation, err := game.Annotate(2)
+			So(err, ShouldBeNil)
+			annotation.Comments = append(annotation.Comments, "The open game")
+			annotation.NAGs = append(annotation.NAGs, 1)
+			again, _ := game.Annotate(2)
+			So(again, ShouldResemble, &Annotation{Comments: []string{"The open game"}, NAGs: []int{1}})
+			_, err = game.Annotate(3)
+			So(err, ShouldResemble, &errors.PlyError{Ply: 3, Plies: 2})
+			Convey("And drop annotations of moves replaced by a new move", func() {
+				So(game.Undo(), ShouldBeTrue)
+				playMoves("c7c5")
+				annotation, _ := game.Annotate(2)
+				So(annotation, ShouldResemble, &Annotation{})
+			})
+		})
+		Convey("Changing a copy of the start position should not change the game", func() {
+			start := game.StartPosition()
+			So(start.MakeMove(mustParseMove("e2e4")), ShouldBeNil)
+			So(game.StartPosition().String(), ShouldEqual, startingFen)
+			So(game.Position().String(), ShouldEqual, startingFen)
+		})
+	})
+}
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
 )
 
 func handleUci() <-chan string {
 	infoLines := []string{
 		"id name PetechesChessBot 0.0",
 		"id author Pete 'Peteches' McCabe",
+		"option name UCI_Chess960 type check default false",
 		"uciok",
 	}
 
@@ -25,7 +29,10 @@ func handleUci() <-chan string {
 	return outChan
 }
 
-func scanForCommands(ctx context.Context, r io.Reader) <-chan string {
+// scanForCommands reads commands from r line by line and sends them to the
+// returned channel, which is closed once r is exhausted. quit is called and
+// the channel closed on the quit command.
+func scanForCommands(ctx context.Context, r io.Reader, quit func()) <-chan string {
 	scanner := bufio.NewScanner(r)
 	cmdChan := make(chan string)
 
@@ -43,6 +50,8 @@ func scanForCommands(ctx context.Context, r io.Reader) <-chan string {
 					cmd := scanner.Text()
 					switch cmd {
 					case "quit":
+						quit()
+
 						goto end
 					default:
 						cmdChan <- cmd
@@ -60,5 +69,77 @@ func scanForCommands(ctx context.Context, r io.Reader) <-chan string {
 	return cmdChan
 }
 
+// writeOutput writes a single message from the engine to w, making sure it
+// is terminated by exactly one newline as required by the UCI protocol.
+func writeOutput(w io.Writer, msg string) error {
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+
+	_, err := io.WriteString(w, msg)
+
+	return err
+}
+
+// run wires the engine up to r and w. Commands are read from r and passed to
+// the engine, everything the engine outputs on either its frmEng or debug
+// channel is written to w in the order it is received. Once r is exhausted
+// run waits for the engine to finish the commands it has been sent before
+// returning, the quit command or cancelling ctx stops it straight away.
+func run(ctx context.Context, r io.Reader, w io.Writer) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	toEng, frmEng, debug := engine(ctx)
+	cmdChan := scanForCommands(ctx, r, cancel)
+
+	go func() {
+		// closing toEng tells the engine there are no more commands.
+		defer close(toEng)
+
+		for cmd := range cmdChan {
+			select {
+			case <-ctx.Done():
+				return
+			case toEng <- cmd:
+			}
+		}
+	}()
+
+	// the engine closes both of its channels once it has finished.
+	for frmEng != nil || debug != nil {
+		var msg string
+
+		var ok bool
+
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok = <-frmEng:
+			if !ok {
+				frmEng = nil
+
+				continue
+			}
+		case msg, ok = <-debug:
+			if !ok {
+				debug = nil
+
+				continue
+			}
+		}
+
+		if err := writeOutput(w, msg); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing to Stdout: ", err)
+
+			return
+		}
+	}
+}
+
 func main() {
+	// debug logging goes to stderr and is far too chatty for normal use.
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	run(context.Background(), os.Stdin, os.Stdout)
 }
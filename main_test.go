@@ -1,9 +1,13 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -11,6 +15,7 @@ import (
 //nolint:gochecknoglobals // for testing this is fine
 var uciOkMsg = `id name PetechesChessBot 0.0
 id author Pete 'Peteches' McCabe
+option name UCI_Chess960 type check default false
 uciok
 `
 
@@ -43,32 +48,142 @@ func TestScanForCommands(t *testing.T) {
 		Convey("It should accept a context an io.Reader and return a readonly string channel", func() {
 			ctx, ctxCancel := context.WithCancel(ctx)
 			in := strings.NewReader("hello\n")
-			So(func() { scanForCommands(ctx, in) }, ShouldNotPanic)
+			So(func() { scanForCommands(ctx, in, func() {}) }, ShouldNotPanic)
 			ctxCancel()
 			ctx, ctxCancel = context.WithCancel(ctx)
-			chn := scanForCommands(ctx, in)
+			chn := scanForCommands(ctx, in, func() {})
 			So(chn, ShouldHaveSameTypeAs, make(<-chan string))
 			ctxCancel()
 		})
 		Convey("It should read from the io.Reader", func() {
 			Convey("And quit when the quit command is passed in", func() {
 				in := strings.NewReader("quit\nnotread\n")
-				chn := scanForCommands(ctx, in)
+				quit := false
+				chn := scanForCommands(ctx, in, func() { quit = true })
 				msg := ""
 				for i := range chn {
 					msg += i
 				}
 				So(msg, ShouldEqual, "")
+				So(quit, ShouldBeTrue)
 			})
 			Convey("And send any other messages to the returned channel", func() {
 				in := strings.NewReader("bob\nquit")
-				chn := scanForCommands(ctx, in)
+				chn := scanForCommands(ctx, in, func() {})
 				msg := ""
 				for i := range chn {
 					msg += i
 				}
 				So(msg, ShouldEqual, "bob")
 			})
+			Convey("And not call quit at the end of its input", func() {
+				quit := false
+				chn := scanForCommands(ctx, strings.NewReader("bob\n"), func() { quit = true })
+				for range chn {
+				}
+				So(quit, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestWriteOutput(t *testing.T) {
+	Convey("Given a writeOutput function", t, func() {
+		Convey("It should write the message followed by a newline", func() {
+			out := &bytes.Buffer{}
+			err := writeOutput(out, "readyok")
+			So(err, ShouldBeNil)
+			So(out.String(), ShouldEqual, "readyok\n")
+		})
+		Convey("It should not add a newline if the message already has one", func() {
+			out := &bytes.Buffer{}
+			err := writeOutput(out, "uciok\n")
+			So(err, ShouldBeNil)
+			So(out.String(), ShouldEqual, "uciok\n")
+		})
+	})
+}
+
+//nolint:funlen // Convey testing is verbose
+func TestRun(t *testing.T) {
+	Convey("Given a run function", t, func() {
+		inReader, inWriter := io.Pipe()
+		outReader, outWriter := io.Pipe()
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			run(context.Background(), inReader, outWriter)
+		}()
+
+		lines := bufio.NewScanner(outReader)
+		readUntil := func(want string) string {
+			out := ""
+			for lines.Scan() {
+				out += lines.Text() + "\n"
+				if lines.Text() == want {
+					break
+				}
+			}
+
+			return out
+		}
+
+		Convey("It should pass commands to the engine and write the replies", func() {
+			_, err := io.WriteString(inWriter, "uci\n")
+			So(err, ShouldBeNil)
+			So(readUntil("uciok"), ShouldEqual, uciOkMsg)
+
+			_, err = io.WriteString(inWriter, "isready\n")
+			So(err, ShouldBeNil)
+			So(readUntil("readyok"), ShouldEqual, "readyok\n")
+		})
+		Convey("It should write debug output", func() {
+			_, err := io.WriteString(inWriter, "printPosition\n")
+			So(err, ShouldBeNil)
+			So(readUntil("info string 8/8/8/8/8/8/8/8 w - - 0 1"), ShouldEqual, "info string 8/8/8/8/8/8/8/8 w - - 0 1\n")
+		})
+
+		Convey("It should return when quit is received", func() {
+			_, err := io.WriteString(inWriter, "quit\n")
+			So(err, ShouldBeNil)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				So("run did not return", ShouldBeEmpty)
+			}
+		})
+		Convey("It should return when its input is closed", func() {
+			So(inWriter.Close(), ShouldBeNil)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				So("run did not return", ShouldBeEmpty)
+			}
+		})
+
+		inWriter.Close()
+		outReader.Close()
+		<-done
+	})
+}
+
+func TestRunToEOF(t *testing.T) {
+	Convey("Given input which ends without quit", t, func() {
+		Convey("run should write the replies to every command before returning", func() {
+			out := &bytes.Buffer{}
+			run(context.Background(), strings.NewReader("isready\n"), out)
+			So(out.String(), ShouldEqual, "readyok\n")
+		})
+		Convey("run should wait for a search to finish", func() {
+			out := &bytes.Buffer{}
+			run(context.Background(), strings.NewReader("position startpos\ngo depth 3\n"), out)
+			So(out.String(), ShouldContainSubstring, "\nbestmove ")
+		})
+		Convey("run should not write replies to commands after quit", func() {
+			out := &bytes.Buffer{}
+			run(context.Background(), strings.NewReader("quit\nisready\n"), out)
+			So(out.String(), ShouldEqual, "")
 		})
 	})
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+)
+
+// undo records everything about a Position that a move changes and cannot be
+// derived from the move itself.
+type undo struct {
+	move            board.Move
+	captured        board.Piece
+	enPassantTarget board.Square
+	castlingRights  uint8
+	halfmoveClock   uint8
+	fullMoveCounter uint8
+	hash            uint64
+}
+
+// capturedSquare returns the square of the piece captured by move. This is the
+// destination square for everything but en passant captures.
+func capturedSquare(side board.Side, move board.Move) board.Square {
+	if move.IsEnPassant() {
+		return board.EnPassantCapture(side, move.To())
+	}
+
+	return move.To()
+}
+
+func (p *Position) switchSides() {
+	p.SideToMove = uint8(opponent(board.Side(p.SideToMove)))
+}
+
+// MakeMove plays move on the position, provided it is legal. Moves parsed from
+// UCI carry no flags, they are matched against the legal moves so the move
+// played has the correct flags set.
+func (p *Position) MakeMove(move board.Move) *errors.MoveError {
+	if err := p.Board.CheckPromotion(move); err != nil {
+		err.Fen = p.String()
+
+		return err
+	}
+
+	legalMoves := p.LegalMoves()
+
+	// a generated move is played as is, in Chess960 a king move and
+	// castling can go between the same squares.
+	for _, legalMove := range legalMoves {
+		if legalMove == move {
+			p.play(legalMove)
+
+			return nil
+		}
+	}
+
+	for _, legalMove := range legalMoves {
+		if !legalMove.SameMove(move) && !p.castlesAs(legalMove, move) {
+			continue
+		}
+
+		p.play(legalMove)
+
+		return nil
+	}
+
+	return &errors.MoveError{
+		Fen:  p.String(),
+		Err:  "Illegal move.",
+		Move: move.String(),
+	}
+}
+
+// play makes move without checking it is legal, recording what is needed to
+// undo it.
+func (p *Position) play(move board.Move) {
+	side := board.Side(p.SideToMove)
+	src, dst := move.From(), move.To()
+	captureSqr := capturedSquare(side, move)
+	record := undo{
+		move:            move,
+		enPassantTarget: p.EnPassantTarget,
+		castlingRights:  p.CastlingRights,
+		halfmoveClock:   p.HalfmoveClock,
+		fullMoveCounter: p.FullMoveCounter,
+		hash:            p.hash,
+	}
+
+	moving := p.Board.PieceOn(src)
+	lost := p.castlingRightsLost(side, src, dst)
+
+	switch {
+	case move.IsCastle():
+		p.castle(p.castlingPathOf(side, move))
+	default:
+		record.captured = p.Board.PieceOn(captureSqr)
+		if record.captured != nil {
+			p.flip(record.captured, captureSqr)
+		}
+
+		p.flip(moving, src)
+
+		if move.Promotion() == board.NoPieceType {
+			p.flip(moving, dst)
+		} else {
+			p.flip(p.Board.PieceOfType(side, move.Promotion()), dst)
+		}
+	}
+
+	p.hash ^= zobrist.castling[p.CastlingRights] ^ zobrist.enPassant(p.EnPassantTarget)
+
+	p.CastlingRights &^= lost
+	p.EnPassantTarget = 0
+
+	if move.IsDoublePush() {
+		// the target is the square the pawn passed over, which is
+		// where an opposing pawn would capture it.
+		p.EnPassantTarget = board.EnPassantCapture(side, dst)
+	}
+
+	p.hash ^= zobrist.castling[p.CastlingRights] ^ zobrist.enPassant(p.EnPassantTarget)
+
+	if _, isPawn := moving.(*board.Pawns); isPawn || record.captured != nil {
+		p.HalfmoveClock = 0
+	} else {
+		p.HalfmoveClock++
+	}
+
+	if side == board.Black {
+		p.FullMoveCounter++
+	}
+
+	p.switchSides()
+	p.hash ^= zobrist.blackToMove
+
+	p.history = append(p.history, record)
+}
+
+// castle moves the king and rook along path. In Chess960 the king can land on
+// the rook's square, or the rook on the king's, so both are lifted before
+// either is put down.
+func (p *Position) castle(path castlingPath) {
+	king, rook := p.Board.PieceOn(path.king), p.Board.PieceOn(path.rook)
+
+	p.flip(king, path.king)
+	p.flip(rook, path.rook)
+	p.flip(king, path.dst)
+	p.flip(rook, path.rookDst)
+}
+
+// UnmakeMove takes back the last move made, restoring the position exactly
+// as it was. It does nothing if no moves have been made.
+func (p *Position) UnmakeMove() {
+	if len(p.history) == 0 {
+		return
+	}
+
+	record := p.history[len(p.history)-1]
+	p.history = p.history[:len(p.history)-1]
+
+	p.switchSides()
+
+	src, dst := record.move.From(), record.move.To()
+
+	if record.move.IsCastle() {
+		path := p.castlingPathOf(board.Side(p.SideToMove), record.move)
+		king, rook := p.Board.PieceOn(path.dst), p.Board.PieceOn(path.rookDst)
+		p.Board.Flip(king, path.dst)
+		p.Board.Flip(rook, path.rookDst)
+		p.Board.Flip(king, path.king)
+		p.Board.Flip(rook, path.rook)
+	} else {
+		moving := p.Board.PieceOn(dst)
+		p.Board.Flip(moving, dst)
+
+		// a promoted piece goes back to being a pawn.
+		if record.move.Promotion() != board.NoPieceType {
+			moving = p.Board.PieceOfType(board.Side(p.SideToMove), board.PawnType)
+		}
+
+		p.Board.Flip(moving, src)
+	}
+
+	if record.captured != nil {
+		captureSqr := capturedSquare(board.Side(p.SideToMove), record.move)
+		p.Board.Flip(record.captured, captureSqr)
+	}
+
+	p.EnPassantTarget = record.enPassantTarget
+	p.CastlingRights = record.castlingRights
+	p.HalfmoveClock = record.halfmoveClock
+	p.FullMoveCounter = record.fullMoveCounter
+	p.hash = record.hash
+}
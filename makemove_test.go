@@ -0,0 +1,210 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func mustParseMove(uci string) board.Move {
+	move, err := board.ParseMove(uci)
+	if err != nil {
+		panic(err)
+	}
+
+	return move
+}
+
+//nolint:funlen // Convey testing is verbose
+func TestMakeMove(t *testing.T) {
+	Convey("Given a Position", t, func() {
+		pos := NewPosition()
+		So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+		Convey("MakeMove() should play a legal move", func() {
+			So(pos.MakeMove(mustParseMove("g1f3")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKB1R b KQkq - 1 1")
+			So(pos.MakeMove(mustParseMove("e7e6")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "rnbqkbnr/pppp1ppp/4p3/8/8/5N2/PPPPPPPP/RNBQKB1R w KQkq - 0 2")
+		})
+		Convey("MakeMove() should capture pieces", func() {
+			So(pos.SetPositionFromFen("4k3/8/8/3p4/8/8/8/3RK3 w - - 5 20"), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("d1d5")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "4k3/8/8/3R4/8/8/8/4K3 b - - 0 20")
+			So(pos.Board.BlackPawns.BitBoard.Board, ShouldEqual, 0)
+		})
+		Convey("MakeMove() should castle, moving the rook too", func() {
+			testCases := map[string]string{
+				"e1g1": "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 1",
+				"e1c1": "r3k2r/8/8/8/8/8/8/2KR3R b kq - 1 1",
+			}
+			for move, expected := range testCases {
+				So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), ShouldBeNil)
+				So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+				So(pos.String(), ShouldEqual, expected)
+			}
+			testCases = map[string]string{
+				"e8g8": "r4rk1/8/8/8/8/8/8/R3K2R w KQ - 1 2",
+				"e8c8": "2kr3r/8/8/8/8/8/8/R3K2R w KQ - 1 2",
+			}
+			for move, expected := range testCases {
+				So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1"), ShouldBeNil)
+				So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+				So(pos.String(), ShouldEqual, expected)
+			}
+		})
+		Convey("MakeMove() should revoke castling rights", func() {
+			testCases := map[string]string{
+				"e1e2": "r3k2r/8/8/8/8/8/4K3/R6R b kq - 1 1",
+				"h1h5": "r3k2r/8/8/7R/8/8/8/R3K3 b Qkq - 1 1",
+				"a1a5": "r3k2r/8/8/R7/8/8/8/4K2R b Kkq - 1 1",
+				"a1a8": "R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 1",
+				"h1h8": "r3k2R/8/8/8/8/8/8/R3K3 b Qq - 0 1",
+			}
+			for move, expected := range testCases {
+				So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), ShouldBeNil)
+				So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+				So(pos.String(), ShouldEqual, expected)
+			}
+			So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("e1d1")), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("e8d8")), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("d1e1")), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("d8e8")), ShouldBeNil)
+			So(pos.String(), ShouldEqual, "r3k2r/8/8/8/8/8/8/R3K2R w - - 4 3")
+			So(pos.MakeMove(mustParseMove("e1g1")), ShouldNotBeNil)
+		})
+		Convey("MakeMove() should set the en passant target after a double push", func() {
+			So(pos.MakeMove(mustParseMove("e2e4")), ShouldBeNil)
+			So(pos.EnPassantTarget, ShouldEqual, board.E3)
+			So(pos.MakeMove(mustParseMove("c7c5")), ShouldBeNil)
+			So(pos.EnPassantTarget, ShouldEqual, board.C6)
+			Convey("And clear it after any other move", func() {
+				So(pos.MakeMove(mustParseMove("g1f3")), ShouldBeNil)
+				So(pos.EnPassantTarget, ShouldEqual, 0)
+				So(pos.MakeMove(mustParseMove("d7d6")), ShouldBeNil)
+				So(pos.EnPassantTarget, ShouldEqual, 0)
+			})
+		})
+		Convey("MakeMove() should capture en passant", func() {
+			testCases := map[string]string{
+				"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1": "e5d6",
+				"4k3/8/8/8/3pP3/8/8/4K3 b - e3 0 1": "d4e3",
+			}
+			expected := map[string]string{
+				"e5d6": "4k3/8/3P4/8/8/8/8/4K3 b - - 0 1",
+				"d4e3": "4k3/8/8/8/8/4p3/8/4K3 w - - 0 2",
+			}
+			for fen, move := range testCases {
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+				So(pos.String(), ShouldEqual, expected[move])
+				pos.UnmakeMove()
+				So(pos.String(), ShouldEqual, fen)
+			}
+		})
+		Convey("MakeMove() should not capture en passant if it exposes the king along the rank", func() {
+			So(pos.SetPositionFromFen("8/8/8/K2pP2r/8/8/8/4k3 w - d6 0 1"), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("e5d6")), ShouldNotBeNil)
+			So(pos.SetPositionFromFen("8/8/8/8/k2Pp2R/8/8/4K3 b - d3 0 1"), ShouldBeNil)
+			So(pos.MakeMove(mustParseMove("e4d3")), ShouldNotBeNil)
+		})
+		Convey("MakeMove() should promote pawns to the chosen piece", func() {
+			testCases := []struct {
+				fen      string
+				move     string
+				expected string
+			}{
+				{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", "1Q2k3/8/8/8/8/8/8/4K3 b - - 0 1"},
+				{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8r", "1R2k3/8/8/8/8/8/8/4K3 b - - 0 1"},
+				{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8b", "1B2k3/8/8/8/8/8/8/4K3 b - - 0 1"},
+				{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8n", "1N2k3/8/8/8/8/8/8/4K3 b - - 0 1"},
+				{"r3k3/1P6/8/8/8/8/8/4K3 w q - 5 1", "b7a8q", "Q3k3/8/8/8/8/8/8/4K3 b - - 0 1"},
+				{"4k3/8/8/8/8/8/6p1/4K2R b K - 0 1", "g2h1n", "4k3/8/8/8/8/8/8/4K2n w - - 0 2"},
+			}
+			for _, tc := range testCases {
+				So(pos.SetPositionFromFen(tc.fen), ShouldBeNil)
+				So(pos.MakeMove(mustParseMove(tc.move)), ShouldBeNil)
+				So(pos.String(), ShouldEqual, tc.expected)
+				pos.UnmakeMove()
+				So(pos.String(), ShouldEqual, tc.fen)
+			}
+		})
+		Convey("MakeMove() should return a MoveError for missing or illegal promotions", func() {
+			testCases := []struct {
+				fen  string
+				move board.Move
+				err  string
+			}{
+				{
+					"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", mustParseMove("b7b8"),
+					"Illegal move, a pawn reaching the last rank must be promoted.",
+				},
+				{
+					startingFen, mustParseMove("e2e4q"),
+					"Illegal move, only a pawn reaching the last rank can be promoted.",
+				},
+				{
+					"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", mustParseMove("e1e2q"),
+					"Illegal move, only a pawn reaching the last rank can be promoted.",
+				},
+				{
+					"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", board.NewMove(board.B7, board.B8, board.KingType),
+					"Illegal move, pawns can only be promoted to a queen, rook, bishop or knight.",
+				},
+			}
+			for _, tc := range testCases {
+				So(pos.SetPositionFromFen(tc.fen), ShouldBeNil)
+				So(pos.MakeMove(tc.move), ShouldResemble, &errors.MoveError{
+					Fen:  tc.fen,
+					Err:  tc.err,
+					Move: tc.move.String(),
+				})
+				So(pos.String(), ShouldEqual, tc.fen)
+			}
+		})
+		Convey("MakeMove() should return a MoveError for illegal moves", func() {
+			err := pos.MakeMove(mustParseMove("e2e5"))
+			So(err, ShouldResemble, &errors.MoveError{
+				Fen:  startingFen,
+				Err:  "Illegal move.",
+				Move: "e2e5",
+			})
+			So(pos.String(), ShouldEqual, startingFen)
+		})
+		Convey("UnmakeMove() should restore the position exactly", func() {
+			testCases := map[string][]string{
+				startingFen: {"e2e4", "d7d5", "e4d5", "d8d5", "b1c3", "d5a5", "f1c4"},
+				"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1": {
+					"e5f7", "e7f7", "f3f6", "g7f6", "d5e6",
+				},
+				"4k3/8/8/8/4P3/8/8/3RK3 b - e3 17 42":  {"e8f7", "d1d7"},
+				"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1": {"e1g1", "e8c8", "f1f8", "d8f8", "a1a8"},
+			}
+			for fen, moves := range testCases {
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				positions := []string{}
+				for _, move := range moves {
+					positions = append(positions, pos.String())
+					So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+					synced := pos.Board.Clone()
+					synced.SyncMailbox()
+					So(pos.Board, ShouldResemble, synced)
+				}
+				for idx := len(positions) - 1; idx >= 0; idx-- {
+					pos.UnmakeMove()
+					So(pos.String(), ShouldEqual, positions[idx])
+				}
+				expected := NewPosition()
+				So(expected.SetPositionFromFen(fen), ShouldBeNil)
+				So(pos.Board, ShouldResemble, expected.Board)
+				So(pos.history, ShouldBeEmpty)
+			}
+		})
+		Convey("UnmakeMove() should do nothing if no moves have been made", func() {
+			pos.UnmakeMove()
+			So(pos.String(), ShouldEqual, startingFen)
+		})
+	})
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"math/bits"
+
+	"github.com/peteches/ChessEngine/board"
+)
+
+//nolint:gochecknoglobals // this is a pseudo const
+var promotionPieces = []board.PieceType{
+	board.QueenType, board.RookType, board.BishopType, board.KnightType,
+}
+
+func opponent(side board.Side) board.Side {
+	if side == board.White {
+		return board.Black
+	}
+
+	return board.White
+}
+
+// LegalMoves returns every legal move for the side to move.
+func (p *Position) LegalMoves() []board.Move {
+	moves := []board.Move{}
+	legal := p.newLegality()
+
+	for _, move := range p.pseudoLegalMoves() {
+		if !legal.allows(p, move) {
+			continue
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves
+}
+
+// InCheck returns true if the side to move is in check.
+func (p *Position) InCheck() bool {
+	return p.Board.Checkers(board.Side(p.SideToMove)).Board != 0
+}
+
+// legality holds what is needed to tell whether a pseudo legal move is legal
+// without playing it.
+type legality struct {
+	side       board.Side
+	kingSqr    board.Square
+	checkers   board.BitBoard
+	kingDanger board.BitBoard
+	// evasions are the squares a piece other than the king can move to
+	// when in check from a single piece: the checker and the squares
+	// between it and the king.
+	evasions board.BitBoard
+	pinRays  map[board.Square]board.BitBoard
+}
+
+func (p *Position) newLegality() *legality {
+	side := board.Side(p.SideToMove)
+	legal := &legality{
+		side:       side,
+		kingSqr:    p.Board.KingSquare(side),
+		checkers:   p.Board.Checkers(side),
+		kingDanger: p.Board.KingDanger(side),
+		pinRays:    map[board.Square]board.BitBoard{},
+	}
+
+	if bits.OnesCount64(legal.checkers.Board) == 1 {
+		checker := board.Square(legal.checkers.Board)
+		legal.evasions = board.Between(legal.kingSqr, checker)
+		legal.evasions.Board |= legal.checkers.Board
+	}
+
+	for _, pin := range p.Board.Pins(side) {
+		legal.pinRays[pin.Pinned] = pin.Ray
+	}
+
+	return legal
+}
+
+func (l *legality) allows(p *Position, move board.Move) bool {
+	src, dst := move.From(), move.To()
+
+	if src == l.kingSqr {
+		return !l.kingDanger.Occupied(dst)
+	}
+
+	checks := bits.OnesCount64(l.checkers.Board)
+
+	// only the king can escape a double check.
+	if checks > 1 {
+		return false
+	}
+
+	if checks == 1 && !l.evasions.Occupied(dst) && !l.checkers.Occupied(capturedSquare(l.side, move)) {
+		return false
+	}
+
+	if ray, pinned := l.pinRays[src]; pinned && !ray.Occupied(dst) {
+		return false
+	}
+
+	// en passant takes two pawns off the same rank at once, which a pin on
+	// either one alone does not show.
+	if move.IsEnPassant() {
+		return !l.exposedByEnPassant(p, move)
+	}
+
+	return true
+}
+
+// exposedByEnPassant returns true if taking both pawns off the board for the
+// en passant capture move lets an enemy rook or queen attack the king along
+// its rank.
+func (l *legality) exposedByEnPassant(p *Position, move board.Move) bool {
+	kingSqr, src := l.kingSqr, move.From()
+	if kingSqr == 0 || kingSqr.Rank() != src.Rank() {
+		return false
+	}
+
+	occupied := p.Board.Occupancy()
+	occupied.Board &^= uint64(src) | uint64(board.EnPassantCapture(l.side, move.To()))
+	occupied.Board |= uint64(move.To())
+
+	enemy := p.Board.ColourOccupancy(opponent(l.side)).Board
+	sliders := p.Board.TypeOccupancy(board.RookType).Board | p.Board.TypeOccupancy(board.QueenType).Board
+
+	return board.RookAttacks(l.kingSqr, occupied).Board&enemy&sliders != 0
+}
+
+// pseudoLegalMoves returns the moves which obey the movement rules of each
+// piece but may leave the moving side in check.
+func (p *Position) pseudoLegalMoves() []board.Move {
+	side := board.Side(p.SideToMove)
+	moves := []board.Move{}
+
+	addTarget := func(src, dst board.Square) {
+		switch {
+		case p.Board.OccupiedBySide(dst, side):
+			return
+		case p.Board.Occupied(dst):
+			moves = append(moves, board.NewMove(src, dst, board.NoPieceType, board.Capture))
+		default:
+			moves = append(moves, board.NewMove(src, dst, board.NoPieceType))
+		}
+	}
+
+	addAttacks := func(src board.Square, attacks board.BitBoard) {
+		for attacks.Board != 0 {
+			addTarget(src, attacks.PopSquare())
+		}
+	}
+
+	var king *board.King
+
+	var queens *board.Queens
+
+	var rooks *board.Rooks
+
+	var bishops *board.Bishops
+
+	var knights *board.Knights
+
+	switch side {
+	case board.White:
+		king, queens, rooks = p.Board.WhiteKing, p.Board.WhiteQueens, p.Board.WhiteRooks
+		bishops, knights = p.Board.WhiteBishops, p.Board.WhiteKnights
+	case board.Black:
+		king, queens, rooks = p.Board.BlackKing, p.Board.BlackQueens, p.Board.BlackRooks
+		bishops, knights = p.Board.BlackBishops, p.Board.BlackKnights
+	}
+
+	for _, src := range king.BitBoard.Squares() {
+		addAttacks(src, board.KingAttacks(src))
+	}
+
+	occupied := p.Board.Occupancy()
+
+	for _, src := range queens.BitBoard.Squares() {
+		addAttacks(src, board.QueenAttacks(src, occupied))
+	}
+
+	for _, src := range rooks.BitBoard.Squares() {
+		addAttacks(src, board.RookAttacks(src, occupied))
+	}
+
+	for _, src := range bishops.BitBoard.Squares() {
+		addAttacks(src, board.BishopAttacks(src, occupied))
+	}
+
+	for _, src := range knights.BitBoard.Squares() {
+		addAttacks(src, board.KnightAttacks(src))
+	}
+
+	moves = append(moves, p.pawnMoves(side)...)
+	moves = append(moves, p.castlingMoves(side)...)
+
+	return moves
+}
+
+const (
+	// thirdRank and sixthRank are the squares a pawn pushing twice passes
+	// through.
+	thirdRank uint64 = 0xff << 16
+	sixthRank uint64 = 0xff << 40
+	// pawnPush is the difference in square index of a pawn moving one rank.
+	pawnPush = 8
+)
+
+//nolint:cyclop // pawns are awkward
+func (p *Position) pawnMoves(side board.Side) []board.Move {
+	moves := []board.Move{}
+
+	var pawns *board.Pawns
+
+	var promotionRank uint8
+
+	// push moves every pawn in a bitboard one rank forward, back moves
+	// them one rank back.
+	var push, back func(uint64) uint64
+
+	var doublePushRank uint64
+
+	switch side {
+	case board.White:
+		pawns = p.Board.WhitePawns
+		promotionRank = board.EighthRank
+		push = func(squares uint64) uint64 { return squares << pawnPush }
+		back = func(squares uint64) uint64 { return squares >> pawnPush }
+		doublePushRank = thirdRank
+	case board.Black:
+		pawns = p.Board.BlackPawns
+		promotionRank = board.FirstRank
+		push = func(squares uint64) uint64 { return squares >> pawnPush }
+		back = func(squares uint64) uint64 { return squares << pawnPush }
+		doublePushRank = sixthRank
+	}
+
+	addMove := func(src, dst board.Square, flags ...board.MoveFlag) {
+		if dst.Rank() != promotionRank {
+			moves = append(moves, board.NewMove(src, dst, board.NoPieceType, flags...))
+
+			return
+		}
+
+		for _, promotion := range promotionPieces {
+			moves = append(moves, board.NewMove(src, dst, promotion, flags...))
+		}
+	}
+
+	empty := ^p.Board.Occupancy().Board
+	singles := push(pawns.BitBoard.Board) & empty
+	doubles := push(singles&doublePushRank) & empty
+
+	for targets := (board.BitBoard{Board: singles}); targets.Board != 0; {
+		dst := targets.PopSquare()
+		addMove(board.Square(back(uint64(dst))), dst)
+	}
+
+	for targets := (board.BitBoard{Board: doubles}); targets.Board != 0; {
+		dst := targets.PopSquare()
+		addMove(board.Square(back(back(uint64(dst)))), dst, board.DoublePush)
+	}
+
+	for _, src := range pawns.BitBoard.Squares() {
+		for captures := board.PawnAttacks(side, src); captures.Board != 0; {
+			dst := captures.PopSquare()
+
+			switch {
+			case p.Board.OccupiedBySide(dst, opponent(side)):
+				addMove(src, dst, board.Capture)
+			case p.EnPassantTarget != 0 && dst == p.EnPassantTarget:
+				victim := board.EnPassantCapture(side, dst)
+				if p.Board.PieceAt(victim) == board.NewColouredPiece(opponent(side), board.PawnType) {
+					addMove(src, dst, board.Capture, board.EnPassant)
+				}
+			}
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) opponentPawns(side board.Side) *board.Pawns {
+	if side == board.White {
+		return p.Board.BlackPawns
+	}
+
+	return p.Board.WhitePawns
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func uciMoves(moves []board.Move) []string {
+	uci := []string{}
+	for _, move := range moves {
+		uci = append(uci, move.String())
+	}
+
+	return uci
+}
+
+//nolint:funlen // Convey testing is verbose
+func TestLegalMoves(t *testing.T) {
+	Convey("Given a Position", t, func() {
+		Convey("LegalMoves() should return the correct number of moves for well known positions", func() {
+			testCases := map[string]int{
+				"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1":                 20,
+				"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1":     48,
+				"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1":                                14,
+				"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1":         6,
+				"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8":                44,
+				"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10": 46,
+			}
+			for fen, expected := range testCases {
+				pos := NewPosition()
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldHaveLength, expected)
+			}
+		})
+		Convey("LegalMoves() should use UCI long algebraic notation", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"), ShouldBeNil)
+			moves := uciMoves(pos.LegalMoves())
+			So(moves, ShouldContain, "e2e4")
+			So(moves, ShouldContain, "g1f3")
+			So(moves, ShouldNotContain, "e2e5")
+		})
+		Convey("LegalMoves() should include castling", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), ShouldBeNil)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "e1g1")
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "e1c1")
+			So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1"), ShouldBeNil)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "e8g8")
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "e8c8")
+			Convey("But not without the castling rights", func() {
+				So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w Kq - 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldContain, "e1g1")
+				So(uciMoves(pos.LegalMoves()), ShouldNotContain, "e1c1")
+			})
+			Convey("But not out of, through or into check", func() {
+				So(pos.SetPositionFromFen("4k3/4r3/8/8/8/8/8/R3K2R w KQ - 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldNotContain, "e1g1")
+				So(uciMoves(pos.LegalMoves()), ShouldNotContain, "e1c1")
+				So(pos.SetPositionFromFen("4k3/8/8/8/8/8/5r2/R3K2R w KQ - 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldNotContain, "e1g1")
+				So(pos.SetPositionFromFen("4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldContain, "e1g1")
+				So(pos.SetPositionFromFen("4k3/8/8/8/8/8/2r5/R3K2R w KQ - 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldNotContain, "e1c1")
+				Convey("Although the rook may pass through an attacked square", func() {
+					So(pos.SetPositionFromFen("4k3/8/8/8/8/8/1r6/R3K2R w KQ - 0 1"), ShouldBeNil)
+					So(uciMoves(pos.LegalMoves()), ShouldContain, "e1c1")
+				})
+			})
+		})
+		Convey("LegalMoves() should include en passant captures", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1"), ShouldBeNil)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "e5d6")
+			Convey("But not if the capture exposes the king along the rank", func() {
+				So(pos.SetPositionFromFen("8/8/8/K2pP2r/8/8/8/4k3 w - d6 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldNotContain, "e5d6")
+			})
+		})
+		Convey("LegalMoves() should flag special moves", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("r3k3/1P6/8/3pP3/8/8/4P3/R3K2R w KQq d6 0 1"), ShouldBeNil)
+			flags := map[string]board.MoveFlag{}
+			for _, move := range pos.LegalMoves() {
+				for _, flag := range []board.MoveFlag{board.Capture, board.Castle, board.EnPassant, board.DoublePush} {
+					if move.Is(flag) {
+						flags[move.String()] |= flag
+					}
+				}
+			}
+			So(flags["e1g1"], ShouldEqual, board.Castle)
+			So(flags["e1c1"], ShouldEqual, board.Castle)
+			So(flags["e5d6"], ShouldEqual, board.Capture|board.EnPassant)
+			So(flags["e2e4"], ShouldEqual, board.DoublePush)
+			So(flags["b7a8q"], ShouldEqual, board.Capture)
+			So(flags["a1a8"], ShouldEqual, board.Capture)
+			So(flags["e2e3"], ShouldEqual, 0)
+		})
+		Convey("LegalMoves() should include all four promotions", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1"), ShouldBeNil)
+			moves := uciMoves(pos.LegalMoves())
+			for _, move := range []string{"a7a8q", "a7a8r", "a7a8b", "a7a8n", "a7b8q", "a7b8r", "a7b8b", "a7b8n"} {
+				So(moves, ShouldContain, move)
+			}
+			So(moves, ShouldNotContain, "a7a8")
+		})
+		Convey("LegalMoves() should exclude moves that leave the king in check", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFenLenient("4k3/8/8/8/8/8/4R3/4K2r w - - 0 1"), ShouldBeNil)
+			moves := uciMoves(pos.LegalMoves())
+			So(moves, ShouldNotContain, "e2e3")
+			So(moves, ShouldNotContain, "e1f1")
+			So(moves, ShouldContain, "e1d2")
+			So(pos.SetPositionFromFen("4k3/8/8/8/8/8/3q4/4K3 w - - 0 1"), ShouldBeNil)
+			moves = uciMoves(pos.LegalMoves())
+			So(moves, ShouldHaveLength, 2)
+			So(moves, ShouldContain, "e1d2")
+			So(moves, ShouldContain, "e1f1")
+		})
+		Convey("LegalMoves() should only block or capture a single checking piece", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("4k3/8/8/8/8/1N6/8/r3K3 w - - 0 1"), ShouldBeNil)
+			So(uciMoves(pos.LegalMoves()), ShouldHaveLength, 5)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "b3a1")
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "b3c1")
+			So(uciMoves(pos.LegalMoves()), ShouldNotContain, "b3d2")
+			Convey("Including capturing a checking pawn en passant", func() {
+				So(pos.SetPositionFromFen("8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1"), ShouldBeNil)
+				So(uciMoves(pos.LegalMoves()), ShouldContain, "e4d3")
+			})
+		})
+		Convey("LegalMoves() should exclude en passant captures which expose the king along its rank", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("8/8/8/KPp4r/8/8/8/4k3 w - c6 0 1"), ShouldBeNil)
+			So(uciMoves(pos.LegalMoves()), ShouldNotContain, "b5c6")
+			So(pos.SetPositionFromFen("8/8/8/KPp1N2r/8/8/8/4k3 w - c6 0 1"), ShouldBeNil)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, "b5c6")
+		})
+		Convey("LegalMoves() should only move the king out of double check", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("4k3/8/8/8/1b6/3n4/8/R3K3 w - - 0 1"), ShouldBeNil)
+			moves := uciMoves(pos.LegalMoves())
+			So(moves, ShouldHaveLength, 3)
+			So(moves, ShouldContain, "e1d1")
+			So(moves, ShouldContain, "e1e2")
+			So(moves, ShouldContain, "e1f1")
+		})
+		Convey("LegalMoves() should keep pinned pieces on the pin ray", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen("4r2k/8/8/8/8/8/4R3/4K3 w - - 0 1"), ShouldBeNil)
+			moves := uciMoves(pos.LegalMoves())
+			for _, move := range []string{"e2e3", "e2e4", "e2e5", "e2e6", "e2e7", "e2e8"} {
+				So(moves, ShouldContain, move)
+			}
+			So(moves, ShouldNotContain, "e2d2")
+			So(moves, ShouldNotContain, "e2a2")
+		})
+		Convey("InCheck() should report whether the side to move is in check", func() {
+			pos := NewPosition()
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			So(pos.InCheck(), ShouldBeFalse)
+			So(pos.SetPositionFromFen("4k3/8/8/8/8/8/8/r3K3 w - - 0 1"), ShouldBeNil)
+			So(pos.InCheck(), ShouldBeTrue)
+			So(pos.SetPositionFromFenLenient("4k3/8/8/8/8/8/8/r3K3 b - - 0 1"), ShouldBeNil)
+			So(pos.InCheck(), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,260 @@
+package notation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+)
+
+// Position is what reading and writing SAN needs to know about a position.
+type Position interface {
+	fmt.Stringer
+	LegalMoves() []board.Move
+	PieceAt(sqr board.Square) board.ColouredPiece
+	MakeMove(move board.Move) *errors.MoveError
+	UnmakeMove()
+	InCheck() bool
+}
+
+const (
+	kingSideCastle  = "O-O"
+	queenSideCastle = "O-O-O"
+)
+
+// sanPattern matches a SAN move other than castling, once any check,
+// mate or annotation suffix is removed. The groups are the piece, the file
+// and rank of the moving piece where given, the destination and the
+// promotion piece.
+//
+//nolint:gochecknoglobals // compiled once
+var sanPattern = regexp.MustCompile(`^([KQRBN])?([a-h])?([1-8])?x?([a-h][1-8])(?:=?([QRBN]))?$`)
+
+// sanMove is what a SAN string says about the move it describes.
+type sanMove struct {
+	castle    string
+	piece     board.PieceType
+	fromFile  uint8
+	fromRank  uint8
+	dst       board.Square
+	promotion board.PieceType
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var pieceLetters = map[string]board.PieceType{
+	"":  board.PawnType,
+	"K": board.KingType,
+	"Q": board.QueenType,
+	"R": board.RookType,
+	"B": board.BishopType,
+	"N": board.KnightType,
+}
+
+// parseSAN splits san into the parts of the move it describes, returning
+// false if it is not SAN.
+func parseSAN(san string) (sanMove, bool) {
+	san = strings.TrimRight(san, "+#!?")
+
+	switch strings.ReplaceAll(san, "0", "O") {
+	case kingSideCastle:
+		return sanMove{castle: kingSideCastle}, true
+	case queenSideCastle:
+		return sanMove{castle: queenSideCastle}, true
+	}
+
+	groups := sanPattern.FindStringSubmatch(san)
+	if groups == nil {
+		return sanMove{}, false
+	}
+
+	move := sanMove{
+		piece:     pieceLetters[groups[1]],
+		dst:       board.BoardMatrixStoI[strings.ToUpper(groups[4])],
+		promotion: board.NoPieceType,
+	}
+
+	if groups[2] != "" {
+		move.fromFile = groups[2][0] - 'a' + board.AFile
+	}
+
+	if groups[3] != "" {
+		move.fromRank = groups[3][0] - '1' + board.FirstRank
+	}
+
+	if groups[5] != "" {
+		move.promotion = pieceLetters[groups[5]]
+	}
+
+	return move, true
+}
+
+// matches returns true if move, legal in pos, is the move san describes.
+func (san sanMove) matches(pos Position, move board.Move) bool {
+	if san.castle != "" {
+		dst := move.To()
+
+		return move.IsCastle() && (dst.File() == board.GFile) == (san.castle == kingSideCastle)
+	}
+
+	src := move.From()
+
+	switch {
+	case move.IsCastle(),
+		pos.PieceAt(src).Type() != san.piece,
+		move.To() != san.dst,
+		move.Promotion() != san.promotion,
+		san.fromFile != 0 && src.File() != san.fromFile,
+		san.fromRank != 0 && src.Rank() != san.fromRank:
+		return false
+	default:
+		return true
+	}
+}
+
+// ParseSAN returns the legal move in pos described by san, such as Nf3, exd5,
+// O-O-O or e8=Q+. Check and annotation suffixes are not checked.
+func ParseSAN(pos Position, san string) (board.Move, *errors.MoveError) {
+	parsed, ok := parseSAN(san)
+	if !ok {
+		return board.NullMove, &errors.MoveError{
+			Fen:  pos.String(),
+			Err:  "Moves should be in Standard Algebraic Notation, e.g. Nf3 or exd5.",
+			Move: san,
+		}
+	}
+
+	found := []board.Move{}
+
+	for _, move := range pos.LegalMoves() {
+		if parsed.matches(pos, move) {
+			found = append(found, move)
+		}
+	}
+
+	switch len(found) {
+	case 1:
+		return found[0], nil
+	case 0:
+		return board.NullMove, &errors.MoveError{Fen: pos.String(), Err: "Illegal move.", Move: san}
+	default:
+		return board.NullMove, &errors.MoveError{
+			Fen:  pos.String(),
+			Err:  "Ambiguous move, more than one piece can make it.",
+			Move: san,
+		}
+	}
+}
+
+// SAN returns move, which must be legal in pos, in Standard Algebraic
+// Notation.
+func SAN(pos Position, move board.Move) (string, *errors.MoveError) {
+	legalMoves := pos.LegalMoves()
+	legal := board.NullMove
+
+	// in Chess960 a king move and castling can share both squares, the
+	// castle flag tells them apart.
+	for _, legalMove := range legalMoves {
+		if legalMove.SameMove(move) && (legal == board.NullMove || legalMove.IsCastle() == move.IsCastle()) {
+			legal = legalMove
+		}
+	}
+
+	if legal == board.NullMove {
+		return "", &errors.MoveError{Fen: pos.String(), Err: "Illegal move.", Move: move.String()}
+	}
+
+	san := moveText(pos, legal, legalMoves)
+
+	if err := pos.MakeMove(legal); err != nil {
+		return "", err
+	}
+
+	if pos.InCheck() {
+		if len(pos.LegalMoves()) == 0 {
+			san += "#"
+		} else {
+			san += "+"
+		}
+	}
+
+	pos.UnmakeMove()
+
+	return san, nil
+}
+
+// moveText returns the SAN for move without any check suffix.
+func moveText(pos Position, move board.Move, legalMoves []board.Move) string {
+	src, dst := move.From(), move.To()
+
+	if move.IsCastle() {
+		if dst.File() == board.GFile {
+			return kingSideCastle
+		}
+
+		return queenSideCastle
+	}
+
+	pieceType := pos.PieceAt(src).Type()
+	san := ""
+
+	if pieceType == board.PawnType {
+		if move.IsCapture() {
+			san += fileName(src)
+		}
+	} else {
+		san += pieceType.String() + disambiguation(pos, move, legalMoves)
+	}
+
+	if move.IsCapture() {
+		san += "x"
+	}
+
+	san += strings.ToLower(dst.String())
+
+	if move.Promotion() != board.NoPieceType {
+		san += "=" + move.Promotion().String()
+	}
+
+	return san
+}
+
+// disambiguation returns the file, rank or square of the moving piece needed
+// to tell move apart from moves to the same square by other pieces of the
+// same type. The file is preferred, then the rank.
+func disambiguation(pos Position, move board.Move, legalMoves []board.Move) string {
+	src := move.From()
+	pieceType := pos.PieceAt(src).Type()
+	rivals, sameFile, sameRank := false, false, false
+
+	for _, other := range legalMoves {
+		otherSrc := other.From()
+		if other.To() != move.To() || otherSrc == src || other.IsCastle() || pos.PieceAt(otherSrc).Type() != pieceType {
+			continue
+		}
+
+		rivals = true
+		sameFile = sameFile || otherSrc.File() == src.File()
+		sameRank = sameRank || otherSrc.Rank() == src.Rank()
+	}
+
+	switch {
+	case !rivals:
+		return ""
+	case !sameFile:
+		return fileName(src)
+	case !sameRank:
+		return rankName(src)
+	default:
+		return strings.ToLower(src.String())
+	}
+}
+
+func fileName(sqr board.Square) string {
+	return strings.ToLower(sqr.String()[:1])
+}
+
+func rankName(sqr board.Square) string {
+	return sqr.String()[1:]
+}
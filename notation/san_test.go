@@ -0,0 +1,62 @@
+package notation
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/board"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestParseSAN(t *testing.T) {
+	Convey("Given parseSAN()", t, func() {
+		Convey("It should read castling with letter O or digit 0", func() {
+			for san, castle := range map[string]string{
+				"O-O":    kingSideCastle,
+				"0-0":    kingSideCastle,
+				"O-O-O+": queenSideCastle,
+				"0-0-0#": queenSideCastle,
+			} {
+				move, ok := parseSAN(san)
+				So(ok, ShouldBeTrue)
+				So(move.castle, ShouldEqual, castle)
+			}
+		})
+		Convey("It should ignore check, mate and annotation suffixes", func() {
+			move, ok := parseSAN("Nf3+!?")
+			So(ok, ShouldBeTrue)
+			So(move, ShouldResemble, sanMove{
+				piece:     board.KnightType,
+				dst:       board.F3,
+				promotion: board.NoPieceType,
+			})
+		})
+		Convey("It should read the file and rank of the moving piece", func() {
+			move, ok := parseSAN("Rfxd1#")
+			So(ok, ShouldBeTrue)
+			So(move.piece, ShouldEqual, board.RookType)
+			So(move.fromFile, ShouldEqual, board.FFile)
+			So(move.fromRank, ShouldEqual, 0)
+			So(move.dst, ShouldEqual, board.D1)
+
+			move, ok = parseSAN("Qh4e1")
+			So(ok, ShouldBeTrue)
+			So(move.fromFile, ShouldEqual, board.HFile)
+			So(move.fromRank, ShouldEqual, board.FourthRank)
+		})
+		Convey("It should read promotions with or without =", func() {
+			for _, san := range []string{"e8=Q+", "e8Q"} {
+				move, ok := parseSAN(san)
+				So(ok, ShouldBeTrue)
+				So(move.piece, ShouldEqual, board.PawnType)
+				So(move.promotion, ShouldEqual, board.QueenType)
+			}
+		})
+		Convey("It should reject what is not SAN", func() {
+			for _, san := range []string{"", "e2e4x", "Pe4", "Ki9", "O-O-O-O", "e8=K", "nf3"} {
+				_, ok := parseSAN(san)
+				So(ok, ShouldBeFalse)
+			}
+		})
+	})
+}
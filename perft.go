@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+)
+
+// Perft walks the tree of legal moves from position to the given depth and
+// returns the number of leaf nodes. Comparing the result against known counts
+// is the standard way to check move generation.
+func Perft(position *Position, depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	moves := position.LegalMoves()
+
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+
+	for _, move := range moves {
+		position.play(move)
+		nodes += Perft(position, depth-1)
+		position.UnmakeMove()
+	}
+
+	return nodes
+}
+
+// Divide returns the perft count below each legal move in position. Moves are
+// kept apart even where their UCI notation is the same, as a castling move and
+// a king step can be without Chess960 notation. This narrows down which move a
+// miscount is under.
+func Divide(position *Position, depth int) map[board.Move]uint64 {
+	counts := map[board.Move]uint64{}
+
+	for _, move := range position.LegalMoves() {
+		position.play(move)
+		counts[move] = Perft(position, depth-1)
+		position.UnmakeMove()
+	}
+
+	return counts
+}
+
+// handlePerft runs divide on position for the arguments to
+//
+//	go perft <depth>
+//
+// returning a line per move followed by the total.
+func handlePerft(position *Position, args []string, chess960 bool) ([]string, error) {
+	if len(args) != 1 {
+		return nil, &errors.InvalidCommandError{
+			Cmd: strings.Join(append([]string{"go", "perft"}, args...), " "),
+			Err: "Expected a single depth.",
+		}
+	}
+
+	depth, err := strconv.Atoi(args[0])
+	if err != nil || depth < 1 {
+		return nil, &errors.InvalidCommandError{
+			Cmd: "go perft " + args[0],
+			Err: "Depth must be a positive number.",
+		}
+	}
+
+	counts := Divide(position, depth)
+	lines := make([]string, 0, len(counts)+2)
+
+	var total uint64
+
+	for move, count := range counts {
+		total += count
+		lines = append(lines, fmt.Sprintf("%s: %d\n", position.UCIMove(move, chess960), count))
+	}
+
+	sort.Strings(lines)
+
+	return append(lines, "\n", fmt.Sprintf("Nodes searched: %d\n", total)), nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// perftNodes are the published node counts for the standard perft positions,
+// indexed by depth - 1.
+//
+//nolint:gochecknoglobals // test fixture
+var perftNodes = []struct {
+	name  string
+	fen   string
+	nodes []uint64
+}{
+	{"startpos", startingFen, []uint64{20, 400, 8902, 197281}},
+	{
+		"Kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		[]uint64{48, 2039, 97862},
+	},
+	{"Position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []uint64{14, 191, 2812, 43238}},
+	{
+		"Position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		[]uint64{6, 264, 9467},
+	},
+	{"Position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []uint64{44, 1486, 62379}},
+	{
+		"Position 6", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		[]uint64{46, 2079, 89890},
+	},
+}
+
+// chess960PerftNodes are published node counts for Chess960 positions, written
+// in Shredder-FEN.
+//
+//nolint:gochecknoglobals // test fixture
+var chess960PerftNodes = []struct {
+	fen   string
+	nodes []uint64
+}{
+	{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []uint64{21, 528, 12189}},
+	{"2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", []uint64{21, 807, 18002}},
+	{"b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9", []uint64{20, 479, 10471}},
+	{"qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9", []uint64{22, 593, 13440}},
+	{"1nbbnrkr/p1p1ppp1/3p4/1p3P1p/3Pq2P/8/PPP1P1P1/QNBBNRKR w HFhf - 0 9", []uint64{28, 1120, 31058}},
+}
+
+func TestPerft(t *testing.T) {
+	Convey("Given the standard perft positions", t, func() {
+		for _, tc := range perftNodes {
+			nodes := tc.nodes
+			if testing.Short() {
+				nodes = nodes[:2]
+			}
+
+			for idx, expected := range nodes {
+				pos := NewPosition()
+				So(pos.SetPositionFromFen(tc.fen), ShouldBeNil)
+				So(Perft(pos, idx+1), ShouldEqual, expected)
+				So(pos.String(), ShouldEqual, tc.fen)
+			}
+		}
+	})
+	Convey("Given the Chess960 perft positions", t, func() {
+		for _, tc := range chess960PerftNodes {
+			nodes := tc.nodes
+			if testing.Short() {
+				nodes = nodes[:2]
+			}
+
+			for idx, expected := range nodes {
+				pos := NewPosition()
+				So(pos.SetPositionFromFen(tc.fen), ShouldBeNil)
+				fen := pos.String()
+				So(Perft(pos, idx+1), ShouldEqual, expected)
+				So(pos.String(), ShouldEqual, fen)
+			}
+		}
+	})
+}
+
+func TestDivide(t *testing.T) {
+	Convey("Given the starting position", t, func() {
+		pos := NewPosition()
+		So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+		Convey("Divide() should count the nodes below each move", func() {
+			counts := Divide(pos, 3)
+			So(counts, ShouldHaveLength, 20)
+
+			byUCI := map[string]uint64{}
+			var total uint64
+			for move, count := range counts {
+				byUCI[move.String()] = count
+				total += count
+			}
+
+			So(byUCI["e2e4"], ShouldEqual, 600)
+			So(byUCI["g1f3"], ShouldEqual, 440)
+			So(byUCI["a2a3"], ShouldEqual, 380)
+
+			So(total, ShouldEqual, 8902)
+			So(pos.String(), ShouldEqual, startingFen)
+		})
+	})
+}
+
+func TestGoPerft(t *testing.T) {
+	Convey("Given an engine", t, func() {
+		ctx, ctxCancel := context.WithCancel(context.Background())
+		toEng, frmEng, _ := engine(ctx)
+		Convey("go perft should list each move's count followed by the total", func() {
+			toEng <- "position startpos moves e2e4 e7e5"
+			toEng <- "go perft 1"
+			out := []string{}
+			for x := range frmEng {
+				out = append(out, x)
+				if x == "Nodes searched: 29\n" {
+					break
+				}
+			}
+			So(out, ShouldHaveLength, 31)
+			So(out[0], ShouldEqual, "a2a3: 1\n")
+			So(out, ShouldContain, "e1e2: 1\n")
+			So(out[29], ShouldEqual, "\n")
+		})
+		Convey("go perft should use king captures rook castling once UCI_Chess960 is set", func() {
+			toEng <- "setoption name UCI_Chess960 value true"
+			toEng <- "position fen 4k3/8/8/8/8/8/8/R5KR w HA - 0 1"
+			toEng <- "go perft 1"
+			out := []string{}
+			for x := range frmEng {
+				out = append(out, x)
+				if strings.HasPrefix(x, "Nodes searched") {
+					break
+				}
+			}
+			So(out, ShouldContain, "g1h1: 1\n")
+			So(out, ShouldContain, "g1a1: 1\n")
+			So(out, ShouldNotContain, "g1g1: 1\n")
+		})
+		Convey("go perft should count castling and a king step to the same square separately", func() {
+			toEng <- "position fen 4k3/8/8/8/8/8/8/5K1R w H - 0 1"
+			toEng <- "go perft 1"
+			out := []string{}
+			for x := range frmEng {
+				out = append(out, x)
+				if strings.HasPrefix(x, "Nodes searched") {
+					break
+				}
+			}
+			So(out, ShouldHaveLength, 16)
+			So(out[15], ShouldEqual, "Nodes searched: 14\n")
+		})
+		Convey("go perft should report an invalid depth", func() {
+			toEng <- "go perft x"
+			So(<-frmEng, ShouldEqual, "info string Error running perft: "+
+				"Invalid command (go perft x). Depth must be a positive number.")
+		})
+		Reset(ctxCancel)
+	})
+}
@@ -0,0 +1,756 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	"github.com/peteches/ChessEngine/notation"
+)
+
+type pgnTokenKind uint8
+
+const (
+	pgnEOF pgnTokenKind = iota
+	pgnSymbol
+	pgnString
+	pgnComment
+	pgnNAG
+	pgnPeriod
+	pgnOpenTag
+	pgnCloseTag
+	pgnOpenVariation
+	pgnCloseVariation
+)
+
+// pgnToken is a token of PGN text and where it starts.
+type pgnToken struct {
+	kind   pgnTokenKind
+	text   string
+	line   int
+	column int
+}
+
+// pgnResults are the game termination markers.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var pgnResults = map[string]bool{
+	"1-0":     true,
+	"0-1":     true,
+	"1/2-1/2": true,
+	"*":       true,
+}
+
+// suffixNAGs are the NAGs written as move suffixes.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var suffixNAGs = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// pgnLexer splits PGN text into tokens, keeping track of the line and column.
+type pgnLexer struct {
+	reader *bufio.Reader
+	line   int
+	column int
+	// the position before the last rune read, for unread.
+	lastLine   int
+	lastColumn int
+	peeked     *pgnToken
+}
+
+func newPGNLexer(reader io.Reader) *pgnLexer {
+	return &pgnLexer{reader: bufio.NewReader(reader), line: 1, column: 1}
+}
+
+func (l *pgnLexer) read() (rune, bool) {
+	char, _, err := l.reader.ReadRune()
+	if err != nil {
+		return 0, false
+	}
+
+	l.lastLine, l.lastColumn = l.line, l.column
+
+	if char == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
+	return char, true
+}
+
+func (l *pgnLexer) unread() {
+	// only ever called straight after a successful read.
+	_ = l.reader.UnreadRune()
+	l.line, l.column = l.lastLine, l.lastColumn
+}
+
+// readWhile reads runes for as long as keep returns true for them.
+func (l *pgnLexer) readWhile(keep func(rune) bool) string {
+	text := strings.Builder{}
+
+	for {
+		char, ok := l.read()
+		if !ok {
+			return text.String()
+		}
+
+		if !keep(char) {
+			l.unread()
+
+			return text.String()
+		}
+
+		text.WriteRune(char)
+	}
+}
+
+func isSymbolChar(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || strings.ContainsRune("_+#=:-/", char)
+}
+
+// peek returns the next token without consuming it.
+func (l *pgnLexer) peek() (pgnToken, *errors.PGNError) {
+	if l.peeked == nil {
+		token, err := l.scan()
+		if err != nil {
+			return token, err
+		}
+
+		l.peeked = &token
+	}
+
+	return *l.peeked, nil
+}
+
+// next returns and consumes the next token.
+func (l *pgnLexer) next() (pgnToken, *errors.PGNError) {
+	token, err := l.peek()
+	l.peeked = nil
+
+	return token, err
+}
+
+//nolint:cyclop // one case per kind of token
+func (l *pgnLexer) scan() (pgnToken, *errors.PGNError) {
+	for {
+		token := pgnToken{line: l.line, column: l.column}
+
+		char, ok := l.read()
+		if !ok {
+			return token, nil
+		}
+
+		fail := func(format string, args ...interface{}) (pgnToken, *errors.PGNError) {
+			return token, &errors.PGNError{Line: token.line, Column: token.column, Err: fmt.Sprintf(format, args...)}
+		}
+
+		switch {
+		case unicode.IsSpace(char):
+			continue
+		case char == '%' && token.column == 1:
+			// escaped lines are for other programs.
+			l.readWhile(func(char rune) bool { return char != '\n' })
+
+			continue
+		case char == ';':
+			token.kind, token.text = pgnComment, commentText(l.readWhile(func(char rune) bool { return char != '\n' }))
+		case char == '{':
+			token.kind, token.text = pgnComment, commentText(l.readWhile(func(char rune) bool { return char != '}' }))
+			if _, ok := l.read(); !ok {
+				return fail("Unterminated comment.")
+			}
+		case char == '"':
+			text, ok := l.readString()
+			if !ok {
+				return fail("Unterminated string.")
+			}
+
+			token.kind, token.text = pgnString, text
+		case char == '$':
+			token.kind, token.text = pgnNAG, l.readWhile(unicode.IsDigit)
+			if token.text == "" {
+				return fail("Expected a number after $.")
+			}
+		case char == '!' || char == '?':
+			suffix := string(char) + l.readWhile(func(char rune) bool { return char == '!' || char == '?' })
+
+			nag, ok := suffixNAGs[suffix]
+			if !ok {
+				return fail("Unknown move suffix (%s).", suffix)
+			}
+
+			token.kind, token.text = pgnNAG, strconv.Itoa(nag)
+		case char == '.':
+			token.kind = pgnPeriod
+		case char == '*':
+			token.kind, token.text = pgnSymbol, "*"
+		case char == '[':
+			token.kind = pgnOpenTag
+		case char == ']':
+			token.kind = pgnCloseTag
+		case char == '(':
+			token.kind = pgnOpenVariation
+		case char == ')':
+			token.kind = pgnCloseVariation
+		case unicode.IsLetter(char) || unicode.IsDigit(char):
+			token.kind, token.text = pgnSymbol, string(char)+l.readWhile(isSymbolChar)
+		default:
+			return fail("Unexpected character (%c).", char)
+		}
+
+		return token, nil
+	}
+}
+
+// commentText returns a comment with its whitespace collapsed, line breaks
+// in comments are only there to wrap them.
+func commentText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// readString reads the rest of a quoted string, which cannot span lines.
+func (l *pgnLexer) readString() (string, bool) {
+	text := strings.Builder{}
+
+	for {
+		char, ok := l.read()
+		if !ok || char == '\n' {
+			return "", false
+		}
+
+		switch char {
+		case '"':
+			return text.String(), true
+		case '\\':
+			if char, ok = l.read(); !ok || char == '\n' {
+				return "", false
+			}
+		}
+
+		text.WriteRune(char)
+	}
+}
+
+// PGNReader reads the games in PGN text one at a time.
+type PGNReader struct {
+	lexer *pgnLexer
+}
+
+// NewPGNReader returns a PGNReader reading from reader.
+func NewPGNReader(reader io.Reader) *PGNReader {
+	return &PGNReader{lexer: newPGNLexer(reader)}
+}
+
+// ReadPGN reads every game in reader. A malformed game is left out and its
+// error returned with the others, reading carries on with the next game.
+func ReadPGN(reader io.Reader) ([]*Game, []error) {
+	games := []*Game{}
+	errs := []error{}
+	pgnReader := NewPGNReader(reader)
+
+	for {
+		game, err := pgnReader.Next()
+
+		switch {
+		case err == io.EOF: //nolint:errorlint // Next returns io.EOF itself
+			return games, errs
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			games = append(games, game)
+		}
+	}
+}
+
+// Next reads the next game, returning io.EOF once there are no more. If the
+// game is malformed a *errors.PGNError is returned and the rest of the game
+// skipped, so Next can be called again for the following game.
+func (r *PGNReader) Next() (*Game, error) {
+	token, err := r.lexer.peek()
+	if err != nil {
+		r.skipGame()
+
+		return nil, err
+	}
+
+	if token.kind == pgnEOF {
+		return nil, io.EOF
+	}
+
+	game, err := r.readGame()
+	if err != nil {
+		r.skipGame()
+
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// skipGame skips to the end of a malformed game, which is its result or the
+// tags of the next game.
+func (r *PGNReader) skipGame() {
+	inMovetext := false
+	afterOpenTag := false
+
+	for {
+		token, err := r.lexer.peek()
+		if err != nil {
+			// the lexer has moved past the bad text.
+			r.lexer.peeked = nil
+
+			continue
+		}
+
+		switch {
+		case token.kind == pgnEOF:
+			return
+		case token.kind == pgnOpenTag && token.column == 1 && inMovetext:
+			return
+		case token.kind == pgnSymbol && pgnResults[token.text]:
+			r.lexer.peeked = nil
+
+			return
+		case token.kind == pgnSymbol && afterOpenTag,
+			token.kind == pgnOpenTag,
+			token.kind == pgnCloseTag,
+			token.kind == pgnString:
+		default:
+			inMovetext = true
+		}
+
+		afterOpenTag = token.kind == pgnOpenTag
+		r.lexer.peeked = nil
+	}
+}
+
+func (r *PGNReader) fail(token pgnToken, format string, args ...interface{}) *errors.PGNError {
+	return &errors.PGNError{Line: token.line, Column: token.column, Err: fmt.Sprintf(format, args...)}
+}
+
+// expect consumes the next token, which must be of the given kind.
+func (r *PGNReader) expect(kind pgnTokenKind, what string) (pgnToken, *errors.PGNError) {
+	token, err := r.lexer.next()
+	if err != nil {
+		return token, err
+	}
+
+	if token.kind != kind {
+		return token, r.fail(token, "Expected %s.", what)
+	}
+
+	return token, nil
+}
+
+func (r *PGNReader) readTag() (Tag, pgnToken, *errors.PGNError) {
+	if _, err := r.expect(pgnOpenTag, "["); err != nil {
+		return Tag{}, pgnToken{}, err
+	}
+
+	name, err := r.expect(pgnSymbol, "a tag name")
+	if err != nil {
+		return Tag{}, name, err
+	}
+
+	value, err := r.expect(pgnString, "a quoted tag value")
+	if err != nil {
+		return Tag{}, name, err
+	}
+
+	if _, err := r.expect(pgnCloseTag, "]"); err != nil {
+		return Tag{}, name, err
+	}
+
+	return Tag{Name: name.text, Value: value.text}, name, nil
+}
+
+func (r *PGNReader) readGame() (*Game, *errors.PGNError) {
+	tags := []Tag{}
+	position := NewPosition()
+	fen := startingFen
+	fenToken := pgnToken{}
+
+	for {
+		token, err := r.lexer.peek()
+		if err != nil {
+			return nil, err
+		}
+
+		if token.kind != pgnOpenTag {
+			break
+		}
+
+		tag, name, err := r.readTag()
+		if err != nil {
+			return nil, err
+		}
+
+		if tag.Name == "FEN" {
+			fen, fenToken = tag.Value, name
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if err := position.SetPositionFromFen(fen); err != nil {
+		return nil, r.fail(fenToken, "Invalid FEN tag. %s", err)
+	}
+
+	game := NewGameFromPosition(position)
+	for _, tag := range tags {
+		game.SetTag(tag.Name, tag.Value)
+	}
+
+	line, err := r.readLine(position, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if token, _ := r.lexer.peek(); token.kind == pgnSymbol && pgnResults[token.text] {
+		r.lexer.peeked = nil
+		game.SetTag("Result", token.text)
+	}
+
+	for _, move := range line.Moves {
+		// the moves were checked as they were read.
+		_ = game.MakeMove(move)
+	}
+
+	game.annotations = line.Annotations
+
+	return game, nil
+}
+
+// readLine reads moves with their annotations, playing them on position. The
+// main line, at depth 0, ends at a result, the next game or the end of the
+// text and a variation ends at its closing parenthesis. Neither is consumed.
+//
+//nolint:cyclop,funlen // one case per kind of token
+func (r *PGNReader) readLine(position *Position, depth int) (Line, *errors.PGNError) {
+	line := Line{Annotations: []Annotation{{}}}
+
+	for {
+		token, err := r.lexer.peek()
+		if err != nil {
+			return line, err
+		}
+
+		annotation := &line.Annotations[len(line.Moves)]
+
+		switch {
+		case token.kind == pgnEOF || token.kind == pgnOpenTag:
+			if depth > 0 {
+				return line, r.fail(token, "Unterminated variation.")
+			}
+
+			return line, nil
+		case token.kind == pgnCloseVariation:
+			if depth == 0 {
+				return line, r.fail(token, "Unexpected ).")
+			}
+
+			return line, nil
+		case token.kind == pgnSymbol && pgnResults[token.text]:
+			if depth > 0 {
+				return line, r.fail(token, "Unterminated variation.")
+			}
+
+			return line, nil
+		case token.kind == pgnComment:
+			if analysis, ok := parseAnalysis(token.text); ok && len(line.Moves) > 0 && annotation.Analysis == nil {
+				annotation.Analysis = analysis
+			} else {
+				annotation.Comments = append(annotation.Comments, token.text)
+			}
+		case token.kind == pgnNAG:
+			if len(line.Moves) == 0 {
+				return line, r.fail(token, "Annotation glyph before any move.")
+			}
+
+			nag, _ := strconv.Atoi(token.text)
+			annotation.NAGs = append(annotation.NAGs, nag)
+		case token.kind == pgnPeriod:
+		case token.kind == pgnSymbol && strings.Trim(token.text, "0123456789") == "":
+			// move numbers are not checked.
+		case token.kind == pgnSymbol:
+			move, err := notation.ParseSAN(position, token.text)
+			if err != nil {
+				return line, r.fail(token, "Invalid move (%s). %s", token.text, err.Err)
+			}
+
+			// ParseSAN only returns legal moves.
+			_ = position.MakeMove(move)
+			line.Moves = append(line.Moves, move)
+			line.Annotations = append(line.Annotations, Annotation{})
+		case token.kind == pgnOpenVariation:
+			if len(line.Moves) == 0 {
+				return line, r.fail(token, "Variation before any move.")
+			}
+
+			r.lexer.peeked = nil
+			variation, err := r.readVariation(position, line.Moves[len(line.Moves)-1], depth)
+
+			if err != nil {
+				return line, err
+			}
+
+			annotation.Variations = append(annotation.Variations, variation)
+
+			continue
+		default:
+			return line, r.fail(token, "Unexpected token.")
+		}
+
+		r.lexer.peeked = nil
+	}
+}
+
+// readVariation reads a variation on move, the last move played on position,
+// once its opening parenthesis has been read.
+func (r *PGNReader) readVariation(position *Position, move board.Move, depth int) (Line, *errors.PGNError) {
+	position.UnmakeMove()
+
+	variation, err := r.readLine(position, depth+1)
+	if err != nil {
+		return variation, err
+	}
+
+	// readLine stops at the closing parenthesis.
+	r.lexer.peeked = nil
+
+	for range variation.Moves {
+		position.UnmakeMove()
+	}
+
+	// move was legal before the variation was read.
+	_ = position.MakeMove(move)
+
+	return variation, nil
+}
+
+// pgnLineLength is the longest line of movetext written.
+const pgnLineLength = 80
+
+// analysisPattern matches engine analysis written as a comment, as in
+// {+0.35/12 1.5s} or {-M3/20}.
+//
+//nolint:gochecknoglobals // compiled once
+var analysisPattern = regexp.MustCompile(`^([+-])(?:(\d+)\.(\d\d)|M(\d+))/(\d+)(?: (\d+(?:\.\d+)?)s)?$`)
+
+// parseAnalysis reads engine analysis from a comment, returning false if the
+// comment is something else.
+func parseAnalysis(comment string) (*Analysis, bool) {
+	groups := analysisPattern.FindStringSubmatch(comment)
+	if groups == nil {
+		return nil, false
+	}
+
+	sign := 1
+	if groups[1] == "-" {
+		sign = -1
+	}
+
+	analysis := &Analysis{}
+	// the pattern only matches digits.
+	analysis.Depth, _ = strconv.Atoi(groups[5])
+
+	if groups[4] != "" {
+		mate, _ := strconv.Atoi(groups[4])
+		analysis.Mate = sign * mate
+	} else {
+		pawns, _ := strconv.Atoi(groups[2])
+		centipawns, _ := strconv.Atoi(groups[3])
+		analysis.Score = sign * (pawns*100 + centipawns)
+	}
+
+	if groups[6] != "" {
+		analysis.Time, _ = time.ParseDuration(groups[6] + "s")
+	}
+
+	return analysis, true
+}
+
+// analysisComment writes engine analysis as a comment, the score in pawns
+// followed by the depth and the time taken in seconds.
+func analysisComment(analysis *Analysis) string {
+	// a mate takes the place of the score, sign and all.
+	sign := func(value int) (string, int) {
+		if value < 0 {
+			return "-", -value
+		}
+
+		return "+", value
+	}
+
+	var comment string
+
+	if analysis.Mate != 0 {
+		mateSign, mate := sign(analysis.Mate)
+		comment = fmt.Sprintf("%sM%d/%d", mateSign, mate, analysis.Depth)
+	} else {
+		scoreSign, score := sign(analysis.Score)
+		comment = fmt.Sprintf("%s%d.%02d/%d", scoreSign, score/100, score%100, analysis.Depth)
+	}
+
+	if analysis.Time > 0 {
+		comment += " " + strconv.FormatFloat(analysis.Time.Seconds(), 'f', -1, 64) + "s"
+	}
+
+	return comment
+}
+
+// commentWords splits comments into words for wrapping, each comment in
+// braces.
+func commentWords(comments []string) []string {
+	words := []string{}
+
+	for _, comment := range comments {
+		commentWords := strings.Fields(comment)
+		if len(commentWords) == 0 {
+			commentWords = []string{""}
+		}
+
+		commentWords[0] = "{" + commentWords[0]
+		commentWords[len(commentWords)-1] += "}"
+		words = append(words, commentWords...)
+	}
+
+	return words
+}
+
+// movetextWords returns the words of the movetext for line, played from
+// position with the given move number. The moves are left played on position.
+func movetextWords(position *Position, line Line, number int) ([]string, *errors.MoveError) {
+	words := commentWords(line.Annotations[0].Comments)
+	needNumber := true
+
+	for idx, move := range line.Moves {
+		annotation := line.Annotations[idx+1]
+
+		san, err := notation.SAN(position, move)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case position.SideToMove == WHITE:
+			words = append(words, strconv.Itoa(number)+".")
+		case needNumber:
+			words = append(words, strconv.Itoa(number)+"...")
+		}
+
+		words = append(words, san)
+		for _, nag := range annotation.NAGs {
+			words = append(words, "$"+strconv.Itoa(nag))
+		}
+
+		comments := annotation.Comments
+		if annotation.Analysis != nil {
+			comments = append([]string{analysisComment(annotation.Analysis)}, comments...)
+		}
+
+		words = append(words, commentWords(comments)...)
+		needNumber = len(comments) > 0 || len(annotation.Variations) > 0
+
+		for _, variation := range annotation.Variations {
+			variationWords, err := movetextWords(position, variation, number)
+			if err != nil {
+				return nil, err
+			}
+
+			for range variation.Moves {
+				position.UnmakeMove()
+			}
+
+			if len(variationWords) == 0 {
+				continue
+			}
+
+			variationWords[0] = "(" + variationWords[0]
+			variationWords[len(variationWords)-1] += ")"
+			words = append(words, variationWords...)
+		}
+
+		if position.SideToMove == BLACK {
+			number++
+		}
+
+		// SAN has checked the move is legal.
+		_ = position.MakeMove(move)
+	}
+
+	return words, nil
+}
+
+// wrap joins words with spaces into lines no longer than width, unless a
+// single word is longer.
+func wrap(words []string, width int) string {
+	lines := []string{}
+	line := ""
+
+	for _, word := range words {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) > width:
+			lines = append(lines, line)
+			line = word
+		default:
+			line += " " + word
+		}
+	}
+
+	return strings.Join(append(lines, line), "\n")
+}
+
+// WritePGN writes the game in PGN export format followed by a blank line, so
+// games can be written one after another. Every move is written, including
+// any taken back. The result is the game's outcome if it has ended, otherwise
+// the Result tag.
+func (g *Game) WritePGN(writer io.Writer) error {
+	position := g.StartPosition()
+
+	words, err := movetextWords(position, Line{Moves: g.moves, Annotations: g.annotations}, int(position.FullMoveCounter))
+	if err != nil {
+		return err
+	}
+
+	// a draw which must be claimed does not end the game by itself.
+	result, _ := g.Tag("Result")
+	if outcome := position.Outcome(); outcome.Result != NoResult && !outcome.Reason.Claimable() {
+		result = outcome.Result.String()
+	}
+
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	text := strings.Builder{}
+
+	for _, tag := range g.Tags() {
+		value := tag.Value
+		if tag.Name == "Result" {
+			value = result
+		}
+
+		fmt.Fprintf(&text, "[%s \"%s\"]\n", tag.Name, escape.Replace(value))
+	}
+
+	fmt.Fprintf(&text, "\n%s\n\n", wrap(append(words, result), pgnLineLength))
+
+	_, writeErr := io.WriteString(writer, text.String())
+
+	return writeErr
+}
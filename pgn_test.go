@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const operaGame = `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[Round "?"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+[ECO "C41"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 {This is a weak move already.} 4. dxe5 Bxf3
+5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8. Nc3 c6 9. Bg5 {Black is in what's like a
+zugzwang position here.} b5 10. Nxb5! cxb5 11. Bxb5+ Nbd7 12. O-O-O Rd8
+13. Rxd7 Rxd7 14. Rd1 Qe6 15. Bxd7+ Nxd7 16. Qb8+ Nxb8 17. Rd8# 1-0
+`
+
+//nolint:funlen,maintidx // Convey testing is verbose
+func TestPGNReader(t *testing.T) {
+	Convey("Given a PGNReader", t, func() {
+		readOne := func(pgn string) (*Game, error) {
+			return NewPGNReader(strings.NewReader(pgn)).Next()
+		}
+		Convey("It should read a game's tags and moves", func() {
+			game, err := readOne(operaGame)
+			So(err, ShouldBeNil)
+			So(game.Len(), ShouldEqual, 33)
+			So(game.Ply(), ShouldEqual, 33)
+			So(game.Outcome(), ShouldResemble, Outcome{Result: WhiteWins, Reason: Checkmate})
+			So(game.Tags()[:7], ShouldResemble, []Tag{
+				{Name: "Event", Value: "Paris"},
+				{Name: "Site", Value: "Paris FRA"},
+				{Name: "Date", Value: "1858.??.??"},
+				{Name: "Round", Value: "?"},
+				{Name: "White", Value: "Paul Morphy"},
+				{Name: "Black", Value: "Duke Karl / Count Isouard"},
+				{Name: "Result", Value: "1-0"},
+			})
+			eco, _ := game.Tag("ECO")
+			So(eco, ShouldEqual, "C41")
+			Convey("With its comments and move suffixes", func() {
+				annotation, _ := game.Annotate(6)
+				So(annotation.Comments, ShouldResemble, []string{"This is a weak move already."})
+				annotation, _ = game.Annotate(17)
+				So(annotation.Comments, ShouldResemble, []string{"Black is in what's like a zugzwang position here."})
+				annotation, _ = game.Annotate(19)
+				So(annotation.NAGs, ShouldResemble, []int{1})
+			})
+		})
+		Convey("It should read recursive variations, NAGs and both kinds of comment", func() {
+			game, err := readOne(`{Opening} 1. e4 $1 (1. d4 d5 (1... Nf6 2. c4) 2. c4 {Queen's gambit}) ; main line
+1... e5 $2 $14 (1... c5) 2. Nf3 *`)
+			So(err, ShouldBeNil)
+			So(game.Len(), ShouldEqual, 3)
+			So(uciMoves(game.Moves()), ShouldResemble, []string{"e2e4", "e7e5", "g1f3"})
+			intro, _ := game.Annotate(0)
+			So(intro.Comments, ShouldResemble, []string{"Opening"})
+			first, _ := game.Annotate(1)
+			So(first.NAGs, ShouldResemble, []int{1})
+			So(first.Comments, ShouldResemble, []string{"main line"})
+			So(first.Variations, ShouldHaveLength, 1)
+			variation := first.Variations[0]
+			So(uciMoves(variation.Moves), ShouldResemble, []string{"d2d4", "d7d5", "c2c4"})
+			So(variation.Annotations[3].Comments, ShouldResemble, []string{"Queen's gambit"})
+			So(variation.Annotations[2].Variations, ShouldHaveLength, 1)
+			So(uciMoves(variation.Annotations[2].Variations[0].Moves), ShouldResemble, []string{"g8f6", "c2c4"})
+			second, _ := game.Annotate(2)
+			So(second.NAGs, ShouldResemble, []int{2, 14})
+			So(uciMoves(second.Variations[0].Moves), ShouldResemble, []string{"c7c5"})
+			result, _ := game.Tag("Result")
+			So(result, ShouldEqual, "*")
+		})
+		Convey("It should start from the FEN tag", func() {
+			game, err := readOne(`[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/8/R3K3 w Q - 0 1"]
+
+1. O-O-O Kf7 2. Rd7+ 1/2-1/2`)
+			So(err, ShouldBeNil)
+			So(game.StartPosition().String(), ShouldEqual, "4k3/8/8/8/8/8/8/R3K3 w Q - 0 1")
+			So(game.Position().String(), ShouldEqual, "8/3R1k2/8/8/8/8/8/2K5 b - - 3 2")
+			result, _ := game.Tag("Result")
+			So(result, ShouldEqual, "1/2-1/2")
+		})
+		Convey("It should read escaped quotes in tag values and skip escaped lines", func() {
+			game, err := readOne("% produced by hand\n[Event \"The \\\"big\\\" one\"]\n\n1. e4 *")
+			So(err, ShouldBeNil)
+			event, _ := game.Tag("Event")
+			So(event, ShouldEqual, `The "big" one`)
+		})
+		Convey("It should report where a game is malformed", func() {
+			for pgn, want := range map[string]*errors.PGNError{
+				"1. e4 e5 2. Ke3 *":       {Line: 1, Column: 13, Err: "Invalid move (Ke3). Illegal move."},
+				"[Event \"?\"\n\n1. e4 *": {Line: 3, Column: 1, Err: "Expected ]."},
+				"[Event \"?]\n\n1. e4 *":  {Line: 1, Column: 8, Err: "Unterminated string."},
+				"1. e4 (1. d4 *":          {Line: 1, Column: 14, Err: "Unterminated variation."},
+				"1. e4 ) *":               {Line: 1, Column: 7, Err: "Unexpected )."},
+				"( 1. e4 ) *":             {Line: 1, Column: 1, Err: "Variation before any move."},
+				"$1 1. e4 *":              {Line: 1, Column: 1, Err: "Annotation glyph before any move."},
+				"1. e4 {unfinished":       {Line: 1, Column: 7, Err: "Unterminated comment."},
+				"1. e4 e5?!? *":           {Line: 1, Column: 9, Err: "Unknown move suffix (?!?)."},
+				"1. e4 & *":               {Line: 1, Column: 7, Err: "Unexpected character (&)."},
+				"[FEN \"8/8/8/8/8/8/8/8 w - - 0 1\"]\n*": {
+					Line: 1, Column: 2, Err: "Invalid FEN tag. Invalid number of white kings (0) in Fen " +
+						"(8/8/8/8/8/8/8/8 w - - 0 1). Each side must have exactly one king.",
+				},
+			} {
+				pgn, want := pgn, want
+				Convey(pgn, func() {
+					_, err := readOne(pgn)
+					So(err, ShouldResemble, want)
+				})
+			}
+		})
+		Convey("It should carry on with the next game after a malformed one", func() {
+			pgn := `[Event "first"]
+
+1. e4 e5 2. Ke3 Nc6 3. Nf3 1-0
+
+[Event "second"]
+
+1. d4 d5 (1... Nf6 2. Bg5 & ) 2. c4
+
+[Event "third"]
+[Round "1"]
+
+1. c4 *
+`
+			reader := NewPGNReader(strings.NewReader(pgn))
+			_, err := reader.Next()
+			So(err, ShouldResemble, &errors.PGNError{Line: 3, Column: 13, Err: "Invalid move (Ke3). Illegal move."})
+			_, err = reader.Next()
+			So(err, ShouldResemble, &errors.PGNError{Line: 7, Column: 27, Err: "Unexpected character (&)."})
+			game, err := reader.Next()
+			So(err, ShouldBeNil)
+			event, _ := game.Tag("Event")
+			So(event, ShouldEqual, "third")
+			So(uciMoves(game.Moves()), ShouldResemble, []string{"c2c4"})
+			_, err = reader.Next()
+			So(err, ShouldEqual, io.EOF)
+		})
+		Convey("ReadPGN() should return every good game and every error", func() {
+			games, errs := ReadPGN(strings.NewReader(operaGame + "\n1. e4 Ke7 *\n\n1. d4 *\n\n"))
+			So(games, ShouldHaveLength, 2)
+			So(errs, ShouldHaveLength, 1)
+			So(uciMoves(games[1].Moves()), ShouldResemble, []string{"d2d4"})
+		})
+		Convey("An empty file should have no games", func() {
+			games, errs := ReadPGN(strings.NewReader("\n\n"))
+			So(games, ShouldBeEmpty)
+			So(errs, ShouldBeEmpty)
+		})
+	})
+}
+
+//nolint:funlen // Convey testing is verbose
+func TestPGNWriter(t *testing.T) {
+	Convey("Given a Game", t, func() {
+		game := NewGame()
+		playMoves := func(moves ...string) {
+			for _, move := range moves {
+				So(game.MakeMove(mustParseMove(move)), ShouldBeNil)
+			}
+		}
+		writePGN := func(game *Game) string {
+			text := strings.Builder{}
+			So(game.WritePGN(&text), ShouldBeNil)
+
+			return text.String()
+		}
+		Convey("WritePGN() should write the tags and SAN movetext", func() {
+			playMoves("e2e4", "e7e5", "g1f3")
+			game.SetTag("White", `Paul "the pride" Morphy`)
+			So(writePGN(game), ShouldEqual, `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Paul \"the pride\" Morphy"]
+[Black "?"]
+[Result "*"]
+
+1. e4 e5 2. Nf3 *
+
+`)
+		})
+		Convey("WritePGN() should write comments, analysis, NAGs and variations", func() {
+			playMoves("e2e4", "e7e5", "g1f3")
+			intro, _ := game.Annotate(0)
+			intro.Comments = []string{"King's pawn"}
+			first, _ := game.Annotate(1)
+			first.NAGs = []int{1}
+			first.Variations = []Line{{
+				Moves:       []board.Move{mustParseMove("d2d4"), mustParseMove("d7d5")},
+				Annotations: []Annotation{{}, {Comments: []string{"Closed"}}, {}},
+			}}
+			second, _ := game.Annotate(2)
+			second.Analysis = &Analysis{Score: -35, Depth: 12, Time: 1500 * time.Millisecond}
+			So(writePGN(game), ShouldEndWith, "\n\n{King's pawn} 1. e4 $1 (1. d4 {Closed} 1... d5) 1... e5 {-0.35/12 1.5s} 2. Nf3 *\n\n")
+		})
+		Convey("WritePGN() should take the sign of a mate from the mate alone", func() {
+			playMoves("e2e4", "e7e5")
+			first, _ := game.Annotate(1)
+			first.Analysis = &Analysis{Score: -99990, Mate: 5, Depth: 20}
+			second, _ := game.Annotate(2)
+			second.Analysis = &Analysis{Score: 99990, Mate: -5, Depth: 20}
+			So(writePGN(game), ShouldEndWith, "\n\n1. e4 {+M5/20} 1... e5 {-M5/20} *\n\n")
+		})
+		Convey("WritePGN() should number the first move of a game starting with black", func() {
+			So(game.SetPositionFromFen("4k3/8/8/8/8/8/8/R3K3 b Q - 0 40"), ShouldBeNil)
+			playMoves("e8d7", "e1c1")
+			So(writePGN(game), ShouldEndWith, "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/8/R3K3 b Q - 0 40\"]\n\n40... Kd7 41. O-O-O+ *\n\n")
+		})
+		Convey("WritePGN() should write the result of a finished game", func() {
+			playMoves("f2f3", "e7e5", "g2g4", "d8h4")
+			text := writePGN(game)
+			So(text, ShouldContainSubstring, "[Result \"0-1\"]")
+			So(text, ShouldEndWith, "1. f3 e5 2. g4 Qh4# 0-1\n\n")
+		})
+		Convey("WritePGN() should keep the result of a game with a claimable draw", func() {
+			pgn := "[Result \"*\"]\n\n1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 *\n\n"
+			game, err := NewPGNReader(strings.NewReader(pgn)).Next()
+			So(err, ShouldBeNil)
+			So(game.Position().Outcome().Reason, ShouldEqual, ThreefoldRepetition)
+			text := writePGN(game)
+			So(text, ShouldContainSubstring, "[Result \"*\"]")
+			So(text, ShouldEndWith, "4. Ng1 Ng8 *\n\n")
+			again, err := NewPGNReader(strings.NewReader(text)).Next()
+			So(err, ShouldBeNil)
+			So(writePGN(again), ShouldEqual, text)
+		})
+		Convey("WritePGN() should wrap movetext at 80 columns", func() {
+			game, err := NewPGNReader(strings.NewReader(operaGame)).Next()
+			So(err, ShouldBeNil)
+			for _, line := range strings.Split(writePGN(game), "\n") {
+				So(len(line), ShouldBeLessThanOrEqualTo, pgnLineLength)
+			}
+		})
+		Convey("WritePGN() should reject an illegal variation", func() {
+			playMoves("e2e4")
+			first, _ := game.Annotate(1)
+			first.Variations = []Line{{Moves: []board.Move{mustParseMove("e2e5")}, Annotations: []Annotation{{}, {}}}}
+			So(game.WritePGN(&strings.Builder{}), ShouldHaveSameTypeAs, &errors.MoveError{})
+		})
+		Convey("Games should read back as they were written", func() {
+			pgn := operaGame + `
+[Event "Annotated"]
+[SetUp "1"]
+[FEN "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"]
+
+{Both sides can castle} 1. O-O {+0.50/8 0.25s} (1. O-O-O $6 {+M7/30} 1... O-O
+(1... Kf7)) 1... Kd8 $2 {+1.20/9} {A mistake} 2. Ra7 *
+
+`
+			games, errs := ReadPGN(strings.NewReader(pgn))
+			So(errs, ShouldBeEmpty)
+			So(games, ShouldHaveLength, 2)
+			first, _ := games[1].Annotate(1)
+			So(first.Analysis, ShouldResemble, &Analysis{Score: 50, Depth: 8, Time: 250 * time.Millisecond})
+			So(first.Variations[0].Annotations[1].Analysis, ShouldResemble, &Analysis{Mate: 7, Depth: 30})
+			for _, game := range games {
+				written := writePGN(game)
+				again, err := NewPGNReader(strings.NewReader(written)).Next()
+				So(err, ShouldBeNil)
+				So(again.Tags(), ShouldResemble, game.Tags())
+				So(again.Moves(), ShouldResemble, game.Moves())
+				So(again.annotations, ShouldResemble, game.annotations)
+				So(writePGN(again), ShouldEqual, written)
+			}
+		})
+	})
+	Convey("Given an engine", t, func() {
+		ctx, ctxCancel := context.WithCancel(context.Background())
+		toEng, frmEng, debug := engine(ctx)
+		Convey("savePGN should append the game to a file", func() {
+			file := filepath.Join(t.TempDir(), "games.pgn")
+			toEng <- "position startpos moves e2e4 e7e5"
+			toEng <- "savePGN " + file
+			So(<-debug, ShouldEqual, "info string Saved game to "+file)
+			toEng <- "position startpos moves d2d4"
+			toEng <- "savePGN " + file
+			So(<-debug, ShouldEqual, "info string Saved game to "+file)
+			text, err := os.ReadFile(file)
+			So(err, ShouldBeNil)
+			games, errs := ReadPGN(bytes.NewReader(text))
+			So(errs, ShouldBeEmpty)
+			So(games, ShouldHaveLength, 2)
+			So(uciMoves(games[0].Moves()), ShouldResemble, []string{"e2e4", "e7e5"})
+			So(uciMoves(games[1].Moves()), ShouldResemble, []string{"d2d4"})
+		})
+		Convey("savePGN should write the engine's analysis of the moves it chose", func() {
+			file := filepath.Join(t.TempDir(), "games.pgn")
+			toEng <- "position startpos moves e2e4"
+			toEng <- "go depth 2"
+			bestMove := ""
+			for line := range frmEng {
+				if strings.HasPrefix(line, "bestmove ") {
+					bestMove = strings.TrimPrefix(line, "bestmove ")
+
+					break
+				}
+			}
+			// the analysis is kept as the game continues.
+			toEng <- "position startpos moves e2e4 " + bestMove
+			toEng <- "position startpos moves e2e4 " + bestMove + " g1f3"
+			toEng <- "savePGN " + file
+			So(<-debug, ShouldEqual, "info string Saved game to "+file)
+			text, err := os.ReadFile(file)
+			So(err, ShouldBeNil)
+			game, err := NewPGNReader(bytes.NewReader(text)).Next()
+			So(err, ShouldBeNil)
+			So(uciMoves(game.Moves()), ShouldResemble, []string{"e2e4", bestMove, "g1f3"})
+			annotation, _ := game.Annotate(2)
+			So(annotation.Analysis, ShouldNotBeNil)
+			So(annotation.Analysis.Depth, ShouldEqual, 2)
+			for _, ply := range []int{1, 3} {
+				annotation, _ = game.Annotate(ply)
+				So(annotation.Analysis, ShouldBeNil)
+			}
+		})
+		Convey("savePGN should report a missing file name", func() {
+			toEng <- "savePGN"
+			So(<-frmEng, ShouldEqual, "info string Error saving PGN: Invalid command (savePGN). Expected savePGN <file>.")
+		})
+		Reset(ctxCancel)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/peteches/ChessEngine/board"
 	"github.com/peteches/ChessEngine/errors"
@@ -11,6 +12,11 @@ import (
 
 const numFenElements = 6
 
+const (
+	numRanks = 8
+	numFiles = 8
+)
+
 const (
 	WHITE uint8 = iota
 	BLACK
@@ -31,6 +37,13 @@ type Position struct {
 	CastlingRights  uint8
 	HalfmoveClock   uint8
 	FullMoveCounter uint8
+
+	hash    uint64
+	history []undo
+	// castlingRooks holds the square of the rook each castling right
+	// castles with, indexed by the right's bit. A zero square is the
+	// standard one, they only differ in Chess960.
+	castlingRooks [4]board.Square
 }
 
 func (p *Position) setSideToMove(side string) *errors.SideToMoveError {
@@ -52,51 +65,100 @@ func (p *Position) setSideToMove(side string) *errors.SideToMoveError {
 	return nil
 }
 
+// setCastlingRights sets the castling rights from the castling field of a
+// FEN. As well as KQkq it accepts the rook files used by Shredder-FEN and
+// X-FEN for Chess960, where KQkq refer to the outermost rook on that side of
+// the king. The pieces must already be set.
 func (p *Position) setCastlingRights(rights string) *errors.CastlingRightsError {
-	var blackKingSide uint8
+	p.CastlingRights = 0
+	p.castlingRooks = [4]board.Square{}
 
-	var blackQueenSide uint8
+	if rights == "-" {
+		return nil
+	}
 
-	var whiteKingSide uint8
+	for _, char := range rights {
+		var right uint8
 
-	var whiteQueenSide uint8
+		var rook board.Square
 
-	for _, pos := range rights {
-		switch pos {
-		case 'k':
-			{
-				blackKingSide = BlackKingSideAllowed
-			}
-		case 'q':
-			{
-				blackQueenSide = BlackQueenSideAllowed
-			}
-		case 'K':
-			{
-				whiteKingSide = WhiteKingSideAllowed
-			}
-		case 'Q':
-			{
-				whiteQueenSide = WhiteQueenSideAllowed
-			}
-		case '-':
-			{
-				p.CastlingRights = 0
-
-				return nil
-			}
+		switch {
+		case strings.ContainsRune("KQkq", char):
+			right = castlingRightOf(char)
+			rook = p.outermostRook(right)
+		case char >= 'A' && char <= 'H':
+			right, rook = p.rookFileRight(board.White, int(char-'A'))
+		case char >= 'a' && char <= 'h':
+			right, rook = p.rookFileRight(board.Black, int(char-'a'))
 		default:
-			{
-				return &errors.CastlingRightsError{ErrChar: pos}
-			}
+			p.CastlingRights = 0
+
+			return &errors.CastlingRightsError{ErrChar: char}
 		}
-	}
 
-	p.CastlingRights = 0 ^ (blackKingSide | blackQueenSide | whiteKingSide | whiteQueenSide)
+		p.CastlingRights |= right
+		// without a rook to castle with the standard square is kept,
+		// Validate() rejects the right.
+		if rook != 0 {
+			p.setCastlingRook(right, rook)
+		}
+	}
 
 	return nil
 }
 
+// rookFileRight returns the castling right and rook square for a rook on
+// file, counted from 0 for the A file, of side's back rank. It is a king side
+// right if the rook is on the king's right.
+func (p *Position) rookFileRight(side board.Side, file int) (uint8, board.Square) {
+	kingSide, queenSide := sideCastlingRights[side][0], sideCastlingRights[side][1]
+	rankStart := castlingTargets[queenSide].rook.Index()
+	rook := board.SquareAt(rankStart + file)
+
+	kingFile := int(board.EFile - board.AFile)
+	if kingSqr := p.Board.KingSquare(side); kingSqr != 0 && kingSqr.Index()/numFiles == rankStart/numFiles {
+		kingFile = kingSqr.Index() % numFiles
+	}
+
+	if file > kingFile {
+		return kingSide, rook
+	}
+
+	return queenSide, rook
+}
+
+// castlingString returns the castling field of the FEN. Rooks KQkq would not
+// find are written as their file, as in X-FEN.
+func (p *Position) castlingString() string {
+	if p.CastlingRights == 0 {
+		return "-"
+	}
+
+	rights := ""
+
+	for _, right := range allCastlingRights {
+		if p.CastlingRights&right == 0 {
+			continue
+		}
+
+		rook := p.castlingRook(right)
+		if rook == castlingTargets[right].rook || rook == p.outermostRook(right) {
+			rights += string(castlingRightChars[right])
+
+			continue
+		}
+
+		file := rune('A' + rook.Index()%numFiles)
+		if rightSide(right) == board.Black {
+			file = unicode.ToLower(file)
+		}
+
+		rights += string(file)
+	}
+
+	return rights
+}
+
 func (p *Position) setEnPassantTarget(targetSquare string) *errors.EnPassantTargetError {
 	enPassantMatrix := map[string]board.Square{
 		"-":  0,
@@ -119,7 +181,9 @@ func (p *Position) setEnPassantTarget(targetSquare string) *errors.EnPassantTarg
 	}
 
 	var ok bool
-	p.EnPassantTarget, ok = enPassantMatrix[targetSquare]
+	// standard FEN uses lower case squares but upper case are accepted
+	// too.
+	p.EnPassantTarget, ok = enPassantMatrix[strings.ToUpper(targetSquare)]
 
 	if ok {
 		return nil
@@ -128,10 +192,26 @@ func (p *Position) setEnPassantTarget(targetSquare string) *errors.EnPassantTarg
 	return &errors.EnPassantTargetError{ErrTarget: targetSquare}
 }
 
-//nolint:funlen // Cannot really make this any simpler
+// SetPositionFromFen sets the position from fen, which must describe a
+// position that could arise in a game. See Validate() for the checks made.
 func (p *Position) SetPositionFromFen(fen string) error {
+	if err := p.SetPositionFromFenLenient(fen); err != nil {
+		return err
+	}
+
+	return p.Validate()
+}
+
+// SetPositionFromFenLenient sets the position from fen checking only that it
+// is well formed. It allows composed positions which could not arise in a
+// game, such as those with no kings, but such positions may not be playable.
+//
+//nolint:funlen,cyclop // Cannot really make this any simpler
+func (p *Position) SetPositionFromFenLenient(fen string) error {
 	fenElements := strings.Split(fen, " ")
 
+	p.history = nil
+
 	if len(fenElements) != numFenElements {
 		return &errors.InvalidFenstringError{
 			Fen: fen,
@@ -139,6 +219,13 @@ func (p *Position) SetPositionFromFen(fen string) error {
 		}
 	}
 
+	if strings.Count(fenElements[0], "/") != numRanks-1 {
+		return &errors.InvalidFenstringError{
+			Fen: fen,
+			Err: "The board must have 8 ranks",
+		}
+	}
+
 	pieceErr := p.Board.SetPieces(fenElements[0])
 	if pieceErr != nil {
 		pieceErr.Fen = fen
@@ -146,6 +233,15 @@ func (p *Position) SetPositionFromFen(fen string) error {
 		return pieceErr
 	}
 
+	for _, rank := range strings.Split(fenElements[0], "/") {
+		if rankSquares(rank) != numFiles {
+			return &errors.InvalidFenstringError{
+				Fen: fen,
+				Err: "Each rank must have 8 squares",
+			}
+		}
+	}
+
 	stmErr := p.setSideToMove(fenElements[1])
 	if stmErr != nil {
 		stmErr.Fen = fen
@@ -194,9 +290,27 @@ func (p *Position) SetPositionFromFen(fen string) error {
 
 	p.FullMoveCounter = uint8(halfMoveClock)
 
+	p.hash = p.ComputeHash()
+
 	return nil
 }
 
+// rankSquares returns the number of squares described by a rank of a FEN
+// piece placement.
+func rankSquares(rank string) int {
+	squares := 0
+
+	for _, char := range rank {
+		if char >= '1' && char <= '8' {
+			squares += int(char - '0')
+		} else {
+			squares++
+		}
+	}
+
+	return squares
+}
+
 func (p *Position) String() string {
 	fen := ""
 
@@ -212,33 +326,14 @@ func (p *Position) String() string {
 
 	fen += " "
 
-	if p.CastlingRights > 0 {
-		for rights := p.CastlingRights; rights != 0; {
-			switch {
-			case rights&WhiteKingSideAllowed > 0:
-				rights ^= WhiteKingSideAllowed
-				fen += "K"
-			case rights&WhiteQueenSideAllowed > 0:
-				rights ^= WhiteQueenSideAllowed
-				fen += "Q"
-			case rights&BlackKingSideAllowed > 0:
-				rights ^= BlackKingSideAllowed
-				fen += "k"
-			case rights&BlackQueenSideAllowed > 0:
-				rights ^= BlackQueenSideAllowed
-				fen += "q"
-			}
-		}
-	} else {
-		fen += "-"
-	}
+	fen += p.castlingString()
 
 	fen += " "
 
 	if p.EnPassantTarget == 0 {
 		fen += "-"
 	} else {
-		fen += p.EnPassantTarget.String()
+		fen += strings.ToLower(p.EnPassantTarget.String())
 	}
 
 	fen += " "
@@ -260,3 +355,23 @@ func NewPosition() *Position {
 
 	return &pos
 }
+
+// Clone returns a copy of the position which can be changed independently.
+// The copy has no moves to unmake.
+func (p *Position) Clone() *Position {
+	return &Position{
+		Board:           p.Board.Clone(),
+		EnPassantTarget: p.EnPassantTarget,
+		SideToMove:      p.SideToMove,
+		CastlingRights:  p.CastlingRights,
+		HalfmoveClock:   p.HalfmoveClock,
+		FullMoveCounter: p.FullMoveCounter,
+		hash:            p.hash,
+		castlingRooks:   p.castlingRooks,
+	}
+}
+
+// PieceAt returns the piece on sqr, or board.NoPiece if it is empty.
+func (p *Position) PieceAt(sqr board.Square) board.ColouredPiece {
+	return p.Board.PieceAt(sqr)
+}
@@ -52,7 +52,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   0,
 		FullMoveCounter: 1,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - A3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - e3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -69,11 +69,18 @@ var validFenstrings = map[string]Position{
 		},
 		SideToMove:      BLACK,
 		CastlingRights:  0,
-		EnPassantTarget: board.A3,
+		EnPassantTarget: board.E3,
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - B3 4 4": {
+}
+
+// lenientFenstrings are well formed but fail Validate, so are only accepted
+// by SetPositionFromFenLenient.
+//
+//nolint:gochecknoglobals // this is for testing purposes
+var lenientFenstrings = map[string]Position{
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - a3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -90,11 +97,11 @@ var validFenstrings = map[string]Position{
 		},
 		SideToMove:      BLACK,
 		CastlingRights:  0,
-		EnPassantTarget: board.B3,
+		EnPassantTarget: board.A3,
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - C3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - b3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -111,11 +118,11 @@ var validFenstrings = map[string]Position{
 		},
 		SideToMove:      BLACK,
 		CastlingRights:  0,
-		EnPassantTarget: board.C3,
+		EnPassantTarget: board.B3,
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - D3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - c3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -132,11 +139,11 @@ var validFenstrings = map[string]Position{
 		},
 		SideToMove:      BLACK,
 		CastlingRights:  0,
-		EnPassantTarget: board.D3,
+		EnPassantTarget: board.C3,
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - E3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - d3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -153,11 +160,11 @@ var validFenstrings = map[string]Position{
 		},
 		SideToMove:      BLACK,
 		CastlingRights:  0,
-		EnPassantTarget: board.E3,
+		EnPassantTarget: board.D3,
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - F3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - f3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -178,7 +185,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - G3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - g3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -199,7 +206,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - H3 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - h3 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -220,7 +227,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - A6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - a6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -241,7 +248,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - B6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - b6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -262,7 +269,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - C6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - c6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -283,7 +290,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - D6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - d6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -304,7 +311,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - E6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - e6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -325,7 +332,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - F6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - f6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -346,7 +353,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - G6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - g6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -367,7 +374,7 @@ var validFenstrings = map[string]Position{
 		HalfmoveClock:   4,
 		FullMoveCounter: 4,
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - H6 4 4": {
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - h6 4 4": {
 		Board: &board.Board{
 			WhiteKing:    board.NewKing(board.White, board.E1),
 			WhiteQueens:  board.NewQueens(board.White, board.D1),
@@ -392,12 +399,12 @@ var validFenstrings = map[string]Position{
 
 //nolint:gochecknoglobals // this is for testing purposes
 var invalidFenstrings = map[string]error{
-	"rnbfkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - H6 4 4": &errors.PiecePositionError{
-		Fen:      "rnbfkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - H6 4 4",
+	"rnbfkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - h6 4 4": &errors.PiecePositionError{
+		Fen:      "rnbfkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b - h6 4 4",
 		ErrPiece: 'f',
 	},
-	"rnbqkbnr/pppppppp/9/8/4P3/8/PPPP1PPP/RNBQKBNR b - H6 4 4": &errors.PiecePositionError{
-		Fen:      "rnbqkbnr/pppppppp/9/8/4P3/8/PPPP1PPP/RNBQKBNR b - H6 4 4",
+	"rnbqkbnr/pppppppp/9/8/4P3/8/PPPP1PPP/RNBQKBNR b - h6 4 4": &errors.PiecePositionError{
+		Fen:      "rnbqkbnr/pppppppp/9/8/4P3/8/PPPP1PPP/RNBQKBNR b - h6 4 4",
 		ErrPiece: '9',
 	},
 	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR x - H6 4 4": &errors.SideToMoveError{
@@ -408,18 +415,18 @@ var invalidFenstrings = map[string]error{
 		Fen:       "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - H2 4 4",
 		ErrTarget: "H2",
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 e 4": &errors.HalfMoveClockError{
-		Fen:           "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 e 4",
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 e 4": &errors.HalfMoveClockError{
+		Fen:           "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 e 4",
 		HalfMoveClock: "e",
 		Err:           "strconv.ParseUint: parsing \"e\": invalid syntax",
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 -1 4": &errors.HalfMoveClockError{
-		Fen:           "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 -1 4",
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 -1 4": &errors.HalfMoveClockError{
+		Fen:           "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 -1 4",
 		HalfMoveClock: "-1",
 		Err:           "strconv.ParseUint: parsing \"-1\": invalid syntax",
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 1 -1": &errors.FullMoveCounterError{
-		Fen:             "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 1 -1",
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 1 -1": &errors.FullMoveCounterError{
+		Fen:             "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 1 -1",
 		FullMoveCounter: "-1",
 		Err:             "strconv.ParseUint: parsing \"-1\": invalid syntax",
 	},
@@ -435,16 +442,103 @@ var invalidFenstrings = map[string]error{
 		Fen: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w -",
 		Err: "Missing Fen elements",
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3": &errors.InvalidFenstringError{
-		Fen: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3",
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3": &errors.InvalidFenstringError{
+		Fen: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3",
 		Err: "Missing Fen elements",
 	},
-	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 -1": &errors.InvalidFenstringError{
-		Fen: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - E3 -1",
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 -1": &errors.InvalidFenstringError{
+		Fen: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w - e3 -1",
 		Err: "Missing Fen elements",
 	},
 }
 
+// illegalFenstrings are well formed but describe positions which could not
+// arise in a game.
+var illegalFenstrings = map[string]error{
+	"4k3/8/8/8/8/8/4K3 w - - 0 1": &errors.InvalidFenstringError{
+		Fen: "4k3/8/8/8/8/8/4K3 w - - 0 1",
+		Err: "The board must have 8 ranks",
+	},
+	"4k3/8/8/8/8/8/8/4K2 w - - 0 1": &errors.InvalidFenstringError{
+		Fen: "4k3/8/8/8/8/8/8/4K2 w - - 0 1",
+		Err: "Each rank must have 8 squares",
+	},
+	"8/8/8/8/8/8/8/4K3 w - - 0 1": &errors.KingCountError{
+		Fen:   "8/8/8/8/8/8/8/4K3 w - - 0 1",
+		Side:  "black",
+		Count: 0,
+	},
+	"4k3/8/8/8/8/8/8/3KK3 w - - 0 1": &errors.KingCountError{
+		Fen:   "4k3/8/8/8/8/8/8/3KK3 w - - 0 1",
+		Side:  "white",
+		Count: 2,
+	},
+	"4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1": &errors.PieceCountError{
+		Fen:  "4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1",
+		Side: "white",
+		Err:  "A side cannot have more than 8 pawns.",
+	},
+	"nnnk4/pppppppp/8/8/8/8/8/4K3 b - - 0 1": &errors.PieceCountError{
+		Fen:  "nnnk4/pppppppp/8/8/8/8/8/4K3 b - - 0 1",
+		Side: "black",
+		Err:  "There are more promoted pieces than missing pawns.",
+	},
+	"4k2P/8/8/8/8/8/8/4K3 w - - 0 1": &errors.PawnRankError{
+		Fen:    "4k2P/8/8/8/8/8/8/4K3 w - - 0 1",
+		Square: "h8",
+	},
+	"4k3/8/8/8/8/8/8/p3K3 w - - 0 1": &errors.PawnRankError{
+		Fen:    "4k3/8/8/8/8/8/8/p3K3 w - - 0 1",
+		Square: "a1",
+	},
+	"4k3/8/8/8/8/8/8/r3K3 b - - 0 1": &errors.CheckError{
+		Fen: "4k3/8/8/8/8/8/8/r3K3 b - - 0 1",
+		Err: "The side not to move cannot be in check.",
+	},
+	"4k3/8/8/8/8/5n2/3p4/r3K3 w - - 0 1": &errors.CheckError{
+		Fen: "4k3/8/8/8/8/5n2/3p4/r3K3 w - - 0 1",
+		Err: "The side to move cannot be in check from more than two pieces.",
+	},
+	"4k3/8/8/8/8/8/3Pp3/7K w - e3 0 1": &errors.EnPassantError{
+		Fen:    "4k3/8/8/8/8/8/3Pp3/7K w - e3 0 1",
+		Square: "e3",
+		Err:    "The en passant target must be on the sixth rank with white to move or the third with black to move.",
+	},
+	"4k3/8/8/8/8/8/8/4K3 w - e6 0 1": &errors.EnPassantError{
+		Fen:    "4k3/8/8/8/8/8/8/4K3 w - e6 0 1",
+		Square: "e6",
+		Err:    "The pawn which just moved two squares must be in front of the en passant target.",
+	},
+	"4k3/8/8/4P3/8/8/8/4K3 w - e6 0 1": &errors.EnPassantError{
+		Fen:    "4k3/8/8/4P3/8/8/8/4K3 w - e6 0 1",
+		Square: "e6",
+		Err:    "The pawn which just moved two squares must be in front of the en passant target.",
+	},
+	"4k3/4n3/8/4p3/8/8/8/4K3 w - e6 0 1": &errors.EnPassantError{
+		Fen:    "4k3/4n3/8/4p3/8/8/8/4K3 w - e6 0 1",
+		Square: "e6",
+		Err:    "The en passant target and the square the pawn moved from must be empty.",
+	},
+	"4k3/8/4n3/4p3/8/8/8/4K3 w - e6 0 1": &errors.EnPassantError{
+		Fen:    "4k3/8/4n3/4p3/8/8/8/4K3 w - e6 0 1",
+		Square: "e6",
+		Err:    "The en passant target and the square the pawn moved from must be empty.",
+	},
+	"4k3/8/8/8/3P4/3B4/8/4K3 b - d3 0 1": &errors.EnPassantError{
+		Fen:    "4k3/8/8/8/3P4/3B4/8/4K3 b - d3 0 1",
+		Square: "d3",
+		Err:    "The en passant target and the square the pawn moved from must be empty.",
+	},
+	"4k3/8/8/8/8/8/8/4K3 w K - 0 1": &errors.CastlingPiecesError{
+		Fen:   "4k3/8/8/8/8/8/8/4K3 w K - 0 1",
+		Right: 'K',
+	},
+	"4k2r/8/8/8/8/8/8/4K3 w q - 0 1": &errors.CastlingPiecesError{
+		Fen:   "4k2r/8/8/8/8/8/8/4K3 w q - 0 1",
+		Right: 'q',
+	},
+}
+
 //nolint:funlen // Convey testing is verbose
 func TestPosition(t *testing.T) {
 	Convey("Given a NewPosition", t, func() {
@@ -517,6 +611,8 @@ func TestPosition(t *testing.T) {
 					pos := NewPosition()
 					err := pos.SetPositionFromFen(fen)
 					So(err, ShouldEqual, nil)
+					position.Board.SyncMailbox()
+					position.hash = position.ComputeHash()
 					So(*pos, ShouldResemble, position)
 				}
 			})
@@ -527,6 +623,51 @@ func TestPosition(t *testing.T) {
 					So(err, ShouldResemble, expectedErr)
 				}
 			})
+			Convey("Return a typed error if the position could not arise in a game", func() {
+				for fen, expectedErr := range illegalFenstrings {
+					pos := NewPosition()
+					err := pos.SetPositionFromFen(fen)
+					So(err, ShouldResemble, expectedErr)
+				}
+			})
+			Convey("Accept lower case en passant targets", func() {
+				So(pos.SetPositionFromFen("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"), ShouldBeNil)
+				So(pos.EnPassantTarget, ShouldEqual, board.E3)
+			})
+			Convey("Accept upper case en passant targets, writing them in lower case", func() {
+				So(pos.SetPositionFromFen("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq E3 0 1"), ShouldBeNil)
+				So(pos.String(), ShouldEqual, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+			})
+		})
+		Convey("The SetPositionFromFenLenient method should", func() {
+			Convey("Accept positions which could not arise in a game", func() {
+				for fen, expectedErr := range illegalFenstrings {
+					pos := NewPosition()
+					err := pos.SetPositionFromFenLenient(fen)
+					if _, malformed := expectedErr.(*errors.InvalidFenstringError); malformed {
+						So(err, ShouldResemble, expectedErr)
+					} else {
+						So(err, ShouldBeNil)
+						So(pos.String(), ShouldEqual, fen)
+					}
+				}
+			})
+			Convey("Set the position from fen strings which fail validation", func() {
+				for fen, position := range lenientFenstrings {
+					pos := NewPosition()
+					So(pos.SetPositionFromFenLenient(fen), ShouldBeNil)
+					position.Board.SyncMailbox()
+					position.hash = position.ComputeHash()
+					So(*pos, ShouldResemble, position)
+					So(pos.String(), ShouldEqual, fen)
+				}
+			})
+			Convey("Still reject malformed fen strings", func() {
+				for fen, expectedErr := range invalidFenstrings {
+					pos := NewPosition()
+					So(pos.SetPositionFromFenLenient(fen), ShouldResemble, expectedErr)
+				}
+			})
 		})
 		Convey("the String() method should accept no arguments and return the position as a fen string", func() {
 			for fen := range validFenstrings {
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/peteches/ChessEngine/errors"
+	"github.com/peteches/ChessEngine/notation"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestSAN(t *testing.T) {
+	Convey("Given a Position", t, func() {
+		pos := NewPosition()
+		Convey("SAN() should write", func() {
+			for fen, moves := range map[string]map[string]string{
+				startingFen: {"g1f3": "Nf3", "e2e4": "e4"},
+				"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2": {"e4d5": "exd5"},
+				"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1": {
+					"e1g1": "O-O", "e1c1": "O-O-O", "a1a8": "Rxa8+",
+				},
+				"4k3/2P5/8/8/8/8/8/4K3 w - - 0 1":                                  {"c7c8q": "c8=Q+", "c7c8n": "c8=N"},
+				"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2":                                {"e5d6": "exd6"},
+				"6k1/5ppp/8/8/8/8/8/R2r1R1K w - - 0 1":                             {"f1d1": "Rfxd1", "a1d1": "Raxd1"},
+				"3r2k1/5ppp/8/8/8/8/5PPP/R4R1K w - - 0 1":                          {"a1d1": "Rad1", "f1d1": "Rfd1"},
+				"6k1/5ppp/8/8/R7/8/8/R5K1 w - - 0 1":                               {"a1a3": "R1a3", "a4a3": "R4a3"},
+				"6k1/5ppp/8/8/4Q2Q/8/8/K6Q w - - 0 1":                              {"h4e1": "Qh4e1", "e4e1": "Qee1", "h1e1": "Q1e1"},
+				"R2r2k1/5ppp/8/8/8/8/5PPP/3R3K w - - 0 1":                          {"a8d8": "Raxd8#", "d1d8": "Rdxd8#"},
+				"k7/8/8/8/8/8/8/1R2K1R1 w KQ - 0 1":                                {"e1g1": "O-O", "e1c1": "O-O-O"},
+				"6k1/5ppp/8/8/8/8/8/4R1K1 w - - 0 1":                               {"e1e8": "Re8#"},
+				"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3": {"f3e5": "Nxe5"},
+			} {
+				fen, moves := fen, moves
+				Convey(fen, func() {
+					So(pos.SetPositionFromFen(fen), ShouldBeNil)
+					for uci, want := range moves {
+						san, err := notation.SAN(pos, mustParseMove(uci))
+						So(err, ShouldBeNil)
+						So(san, ShouldEqual, want)
+						So(pos.String(), ShouldEqual, fen)
+					}
+				})
+			}
+		})
+		Convey("SAN() should reject an illegal move", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			_, err := notation.SAN(pos, mustParseMove("e2e5"))
+			So(err, ShouldResemble, &errors.MoveError{Fen: startingFen, Err: "Illegal move.", Move: "e2e5"})
+		})
+		Convey("ParseSAN() should find the move", func() {
+			fen := "3r2k1/5ppp/8/8/8/8/5PPP/R4R1K w - - 0 1"
+			So(pos.SetPositionFromFen(fen), ShouldBeNil)
+			for san, uci := range map[string]string{"Rad1": "a1d1", "Rfd1+": "f1d1", "R1d1": "", "h3": "h2h3"} {
+				move, err := notation.ParseSAN(pos, san)
+				if uci == "" {
+					So(err, ShouldResemble, &errors.MoveError{
+						Fen:  fen,
+						Err:  "Ambiguous move, more than one piece can make it.",
+						Move: san,
+					})
+
+					continue
+				}
+				So(err, ShouldBeNil)
+				So(move.String(), ShouldEqual, uci)
+			}
+		})
+		Convey("ParseSAN() should read castling in Chess960", func() {
+			So(pos.SetPositionFromFen("k7/8/8/8/8/8/8/1R2K1R1 w GB - 0 1"), ShouldBeNil)
+			move, err := notation.ParseSAN(pos, "0-0-0")
+			So(err, ShouldBeNil)
+			So(move.IsCastle(), ShouldBeTrue)
+			So(move.String(), ShouldEqual, "e1c1")
+		})
+		Convey("ParseSAN() should reject illegal and malformed moves", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			_, err := notation.ParseSAN(pos, "Ke2")
+			So(err, ShouldResemble, &errors.MoveError{Fen: startingFen, Err: "Illegal move.", Move: "Ke2"})
+			_, err = notation.ParseSAN(pos, "e2-e4")
+			So(err, ShouldResemble, &errors.MoveError{
+				Fen:  startingFen,
+				Err:  "Moves should be in Standard Algebraic Notation, e.g. Nf3 or exd5.",
+				Move: "e2-e4",
+			})
+		})
+		Convey("Every legal move should read back from its SAN", func() {
+			for _, fen := range []string{
+				startingFen,
+				"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+				"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+				"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+				"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+				"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			} {
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				for _, move := range pos.LegalMoves() {
+					san, err := notation.SAN(pos, move)
+					So(err, ShouldBeNil)
+					parsed, err := notation.ParseSAN(pos, san)
+					So(err, ShouldBeNil)
+					So(parsed, ShouldEqual, move)
+				}
+			}
+		})
+	})
+}
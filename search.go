@@ -0,0 +1,438 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+)
+
+const (
+	mateScore = 100000
+	infinity  = mateScore + 1
+	// scores beyond mateThreshold either way are mates.
+	mateThreshold = mateScore - 1000
+	// maxDepth bounds iterative deepening when only a time limit is set.
+	maxDepth = 64
+	// the clock is only read every nodesPerTimeCheck nodes.
+	nodesPerTimeCheck = 1024
+	drawPlies         = 100
+	// defaultMoveTime is spent on a search without a depth or clock it can
+	// use, such as go nodes or an epd suite given no limits.
+	defaultMoveTime = time.Second
+	// defaultMovesToGo is how many more moves the time left is shared
+	// between when the GUI does not say.
+	defaultMovesToGo = 30
+)
+
+// goNumberParameters are the go command's parameters followed by a number.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var goNumberParameters = map[string]bool{
+	"depth": true, "movetime": true, "nodes": true, "mate": true, "movestogo": true,
+	"wtime": true, "btime": true, "winc": true, "binc": true,
+}
+
+// clockParameters are the go command's time left and increment parameters
+// for each side.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var clockParameters = map[board.Side][2]string{
+	board.White: {"wtime", "winc"},
+	board.Black: {"btime", "binc"},
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var pieceValues = map[board.PieceType]int{
+	board.PawnType:   100,
+	board.KnightType: 320,
+	board.BishopType: 330,
+	board.RookType:   500,
+	board.QueenType:  900,
+}
+
+// SearchLimits bound a search, 0 meaning no limit on that count. A search
+// with neither limit set runs to maxDepth. Closing Stop ends the search early.
+// Infinite searches are for the UCI go infinite command, whose best move is
+// held back until the GUI says stop.
+type SearchLimits struct {
+	Depth    int
+	MoveTime time.Duration
+	Infinite bool
+	Stop     <-chan struct{}
+}
+
+// SearchResult is the best move found by a search, its score in centipawns
+// for the side to move and the principal variation, the line of best play
+// starting with the move.
+type SearchResult struct {
+	Move  board.Move
+	Score int
+	Depth int
+	PV    []board.Move
+}
+
+// Mate returns the number of moves to mate when Score is a mate score,
+// negative when the side to move is being mated, otherwise 0.
+func (r SearchResult) Mate() int {
+	switch {
+	case r.Score > mateThreshold:
+		return (mateScore - r.Score + 1) / 2
+	case r.Score < -mateThreshold:
+		return -(mateScore + r.Score) / 2
+	default:
+		return 0
+	}
+}
+
+// parseSearchLimits reads search limits from the arguments to cmd
+//
+//	depth <plies> movetime <milliseconds>
+//
+// either of which may be left out. With neither the search gets
+// defaultMoveTime. The depth is capped at maxDepth.
+func parseSearchLimits(cmd string, args []string) (SearchLimits, error) {
+	limits := SearchLimits{}
+	fail := &errors.InvalidCommandError{
+		Cmd: strings.Join(append([]string{cmd}, args...), " "),
+		Err: "Expected depth <plies> or movetime <milliseconds>.",
+	}
+
+	if len(args) == 0 {
+		limits.MoveTime = defaultMoveTime
+
+		return limits, nil
+	}
+
+	if len(args)%2 != 0 {
+		return limits, fail
+	}
+
+	for idx := 0; idx < len(args); idx += 2 {
+		value, err := strconv.Atoi(args[idx+1])
+		if err != nil || value <= 0 {
+			return limits, fail
+		}
+
+		switch args[idx] {
+		case "depth":
+			limits.Depth = value
+			if limits.Depth > maxDepth {
+				limits.Depth = maxDepth
+			}
+		case "movetime":
+			limits.MoveTime = time.Duration(value) * time.Millisecond
+		default:
+			return limits, fail
+		}
+	}
+
+	return limits, nil
+}
+
+// parseGoLimits reads search limits for side to move from the arguments to
+// the UCI go command
+//
+//	go [depth <plies>] [movetime <milliseconds>] [wtime <milliseconds>] [btime <milliseconds>]
+//	   [winc <milliseconds>] [binc <milliseconds>] [movestogo <moves>] [infinite] ...
+//
+// side's share of its time left is used when there is no movetime, and no time
+// at all with infinite. The other parameters, such as nodes, are not supported
+// by the search and are skipped, falling back to defaultMoveTime when there is
+// nothing else to go on. The limits can always be searched with, the error reports any
+// parameters skipped for not having a number.
+func parseGoLimits(side board.Side, args []string) (SearchLimits, error) {
+	limits := SearchLimits{}
+	values := map[string]int{}
+	malformed := []string{}
+
+	for idx := 0; idx < len(args); idx++ {
+		name := args[idx]
+		if name == "infinite" {
+			limits.Infinite = true
+		}
+
+		if !goNumberParameters[name] {
+			continue
+		}
+
+		if idx+1 == len(args) {
+			malformed = append(malformed, name)
+
+			break
+		}
+
+		value, err := strconv.Atoi(args[idx+1])
+		if err != nil || value < 0 {
+			malformed = append(malformed, name)
+
+			continue
+		}
+
+		values[name] = value
+		idx++
+	}
+
+	if depth := values["depth"]; depth > 0 {
+		limits.Depth = depth
+		if limits.Depth > maxDepth {
+			limits.Depth = maxDepth
+		}
+	}
+
+	timeLeft, clocked := values[clockParameters[side][0]]
+
+	switch {
+	case limits.Infinite:
+		// the search goes on until it is stopped.
+	case values["movetime"] > 0:
+		limits.MoveTime = time.Duration(values["movetime"]) * time.Millisecond
+	case clocked:
+		movesToGo := values["movestogo"]
+		if movesToGo == 0 {
+			movesToGo = defaultMovesToGo
+		}
+
+		share := timeLeft/movesToGo + values[clockParameters[side][1]]/2
+		// never more than half the time left, nor nothing at all.
+		if share > timeLeft/2 {
+			share = timeLeft / 2
+		}
+
+		if share < 1 {
+			share = 1
+		}
+
+		limits.MoveTime = time.Duration(share) * time.Millisecond
+	case limits.Depth == 0:
+		limits.MoveTime = defaultMoveTime
+	}
+
+	if len(malformed) > 0 {
+		return limits, &errors.InvalidCommandError{
+			Cmd: strings.Join(append([]string{"go"}, args...), " "),
+			Err: fmt.Sprintf("Expected a number after %s.", strings.Join(malformed, ", ")),
+		}
+	}
+
+	return limits, nil
+}
+
+// evaluate returns the material balance for the side to move.
+func evaluate(p *Position) int {
+	score := 0
+	side := board.Side(p.SideToMove)
+	us, them := p.Board.ColourOccupancy(side).Board, p.Board.ColourOccupancy(opponent(side)).Board
+
+	for pieceType, value := range pieceValues {
+		pieces := p.Board.TypeOccupancy(pieceType).Board
+		score += value * (bits.OnesCount64(pieces&us) - bits.OnesCount64(pieces&them))
+	}
+
+	return score
+}
+
+// orderMoves puts the captures and promotions which win material first, best
+// first, as judged by SEE. Those which lose material go after the quiet moves.
+func orderMoves(position *Position, moves []board.Move) {
+	scores := make(map[board.Move]int, len(moves))
+
+	for _, move := range moves {
+		if move.IsCapture() || move.Promotion() != board.NoPieceType {
+			scores[move] = position.Board.SEE(move)
+		}
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		return scores[moves[i]] > scores[moves[j]]
+	})
+}
+
+type searcher struct {
+	position *Position
+	deadline time.Time
+	stop     <-chan struct{}
+	nodes    int
+	stopped  bool
+	// pv[ply] holds the best line found from ply, in pv[ply][ply:pvLength[ply]].
+	// Each ply copies up the line of the ply below, which is why it is
+	// triangular.
+	pv       [maxDepth + 1][maxDepth + 1]board.Move
+	pvLength [maxDepth + 1]int
+}
+
+// updatePV makes move followed by the line below it the best line from ply.
+func (s *searcher) updatePV(ply int, move board.Move) {
+	s.pv[ply][ply] = move
+	copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pvLength[ply+1]])
+	s.pvLength[ply] = s.pvLength[ply+1]
+}
+
+// expired reports whether the search has run out of time or been stopped.
+func (s *searcher) expired() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+	}
+
+	return !s.deadline.IsZero() && time.Now().After(s.deadline)
+}
+
+// negamax returns the score of the position searched to depth with
+// alpha-beta pruning, ply being the distance from the root.
+func (s *searcher) negamax(depth, ply, alpha, beta int) int {
+	s.nodes++
+	s.pvLength[ply] = ply
+
+	if s.nodes%nodesPerTimeCheck == 0 && s.expired() {
+		s.stopped = true
+	}
+
+	if s.stopped {
+		return 0
+	}
+
+	moves := s.position.LegalMoves()
+
+	switch {
+	case len(moves) == 0 && s.position.InCheck():
+		return -mateScore + ply
+	case len(moves) == 0,
+		s.position.HalfmoveClock >= drawPlies,
+		ply > 0 && s.position.RepetitionCount() > 1:
+		return 0
+	case depth == 0:
+		return evaluate(s.position)
+	}
+
+	orderMoves(s.position, moves)
+
+	for _, move := range moves {
+		s.position.play(move)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		s.position.UnmakeMove()
+
+		if score >= beta {
+			return beta
+		}
+
+		if score > alpha {
+			alpha = score
+			s.updatePV(ply, move)
+		}
+	}
+
+	return alpha
+}
+
+// searchRoot searches every move in the position to depth, returning the
+// best. The result is incomplete if the search is stopped.
+func (s *searcher) searchRoot(moves []board.Move, depth int) SearchResult {
+	result := SearchResult{Move: moves[0], Score: -infinity, Depth: depth}
+
+	for _, move := range moves {
+		s.position.play(move)
+		score := -s.negamax(depth-1, 1, -infinity, -result.Score)
+		s.position.UnmakeMove()
+
+		if s.stopped {
+			break
+		}
+
+		if score > result.Score {
+			result.Move, result.Score = move, score
+			s.updatePV(0, move)
+		}
+	}
+
+	result.PV = append([]board.Move{}, s.pv[0][:s.pvLength[0]]...)
+
+	return result
+}
+
+// Search finds the best move in position within limits by iterative
+// deepening, passing the result of each depth searched to report if it is not
+// nil. The position is left as it was.
+func Search(position *Position, limits SearchLimits, report func(SearchResult)) SearchResult {
+	search := searcher{position: position, stop: limits.Stop}
+	if limits.MoveTime > 0 {
+		search.deadline = time.Now().Add(limits.MoveTime)
+	}
+
+	moves := position.LegalMoves()
+	if len(moves) == 0 {
+		return SearchResult{Move: board.NullMove, Score: search.negamax(0, 0, -infinity, infinity)}
+	}
+
+	orderMoves(position, moves)
+
+	depthLimit := limits.Depth
+	if depthLimit == 0 || depthLimit > maxDepth {
+		depthLimit = maxDepth
+	}
+
+	best := SearchResult{Move: moves[0]}
+
+	for depth := 1; depth <= depthLimit; depth++ {
+		result := search.searchRoot(moves, depth)
+		if search.stopped && depth > 1 {
+			break
+		}
+
+		best = result
+
+		if report != nil {
+			report(result)
+		}
+
+		// the best move so far is searched first next time, which makes
+		// the most of alpha-beta pruning.
+		for idx := range moves {
+			if moves[idx] == result.Move {
+				copy(moves[1:idx+1], moves[:idx])
+				moves[0] = result.Move
+
+				break
+			}
+		}
+
+		// the shortest mate is found first.
+		if search.stopped || result.Mate() != 0 {
+			break
+		}
+	}
+
+	return best
+}
+
+// pvString returns the principal variation pv from position in UCI notation.
+func pvString(position *Position, pv []board.Move, chess960 bool) string {
+	moves := make([]string, 0, len(pv))
+
+	for _, move := range pv {
+		moves = append(moves, position.UCIMove(move, chess960))
+		position.play(move)
+	}
+
+	for range pv {
+		position.UnmakeMove()
+	}
+
+	return strings.Join(moves, " ")
+}
+
+// infoLine returns the UCI info line reporting result, searched in position.
+func infoLine(position *Position, result SearchResult, chess960 bool) string {
+	score := fmt.Sprintf("cp %d", result.Score)
+	if mate := result.Mate(); mate != 0 {
+		score = fmt.Sprintf("mate %d", mate)
+	}
+
+	return fmt.Sprintf("info depth %d score %s pv %s", result.Depth, score, pvString(position, result.PV, chess960))
+}
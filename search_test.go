@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestSearch(t *testing.T) {
+	Convey("Given Search()", t, func() {
+		pos := NewPosition()
+		Convey("It should find a mate in one", func() {
+			fen := "6k1/5ppp/8/8/8/8/8/4R1K1 w - - 0 1"
+			So(pos.SetPositionFromFen(fen), ShouldBeNil)
+			result := Search(pos, SearchLimits{Depth: 3}, nil)
+			So(result.Move.String(), ShouldEqual, "e1e8")
+			So(result.Mate(), ShouldEqual, 1)
+			So(pos.String(), ShouldEqual, fen)
+		})
+		Convey("It should find a mate in two", func() {
+			So(pos.SetPositionFromFen("k7/8/2K5/8/8/8/8/7R w - - 0 1"), ShouldBeNil)
+			result := Search(pos, SearchLimits{Depth: 4}, nil)
+			So(result.Move.String(), ShouldEqual, "c6b6")
+			So(result.Mate(), ShouldEqual, 2)
+			So(result.Depth, ShouldEqual, 3)
+		})
+		Convey("It should collect the principal variation", func() {
+			So(pos.SetPositionFromFen("k7/8/2K5/8/8/8/8/7R w - - 0 1"), ShouldBeNil)
+			depths := []int{}
+			result := Search(pos, SearchLimits{Depth: 5}, func(result SearchResult) {
+				depths = append(depths, result.Depth)
+				So(result.PV[0], ShouldEqual, result.Move)
+			})
+			So(depths, ShouldResemble, []int{1, 2, 3})
+			So(uciMoves(result.PV), ShouldResemble, []string{"c6b6", "a8b8", "h1h8"})
+			So(infoLine(pos, result, false), ShouldEqual, "info depth 3 score mate 2 pv c6b6 a8b8 h1h8")
+		})
+		Convey("It should report being mated", func() {
+			So(pos.SetPositionFromFen("k7/8/1K6/8/8/8/8/7R b - - 0 1"), ShouldBeNil)
+			result := Search(pos, SearchLimits{Depth: 3}, nil)
+			So(result.Mate(), ShouldEqual, -1)
+			So(infoLine(pos, result, false), ShouldEqual, "info depth 2 score mate -1 pv a8b8 h1h8")
+		})
+		Convey("It should win a hanging queen", func() {
+			So(pos.SetPositionFromFen("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1"), ShouldBeNil)
+			result := Search(pos, SearchLimits{Depth: 2}, nil)
+			So(result.Move.String(), ShouldEqual, "d2d5")
+			So(result.Score, ShouldEqual, pieceValues[board.RookType])
+		})
+		Convey("It should return no move when there is none", func() {
+			So(pos.SetPositionFromFen("4R1k1/5ppp/8/8/8/8/8/6K1 b - - 1 1"), ShouldBeNil)
+			result := Search(pos, SearchLimits{Depth: 2}, nil)
+			So(result.Move, ShouldEqual, board.NullMove)
+			So(result.Score, ShouldEqual, -mateScore)
+		})
+		Convey("It should stop when the time is up", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			start := time.Now()
+			result := Search(pos, SearchLimits{MoveTime: 50 * time.Millisecond}, nil)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, result.Move.String())
+			So(pos.String(), ShouldEqual, startingFen)
+		})
+		Convey("It should stop when Stop is closed", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			stop := make(chan struct{})
+			close(stop)
+			start := time.Now()
+			result := Search(pos, SearchLimits{Stop: stop}, nil)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+			So(uciMoves(pos.LegalMoves()), ShouldContain, result.Move.String())
+			So(pos.String(), ShouldEqual, startingFen)
+		})
+	})
+	Convey("Given SearchResult.Mate()", t, func() {
+		So(SearchResult{Score: mateScore - 1}.Mate(), ShouldEqual, 1)
+		So(SearchResult{Score: mateScore - 5}.Mate(), ShouldEqual, 3)
+		So(SearchResult{Score: -mateScore + 4}.Mate(), ShouldEqual, -2)
+		So(SearchResult{Score: 900}.Mate(), ShouldEqual, 0)
+	})
+	Convey("Given parseSearchLimits()", t, func() {
+		Convey("It should read depth and movetime", func() {
+			limits, err := parseSearchLimits("go", []string{"depth", "6", "movetime", "250"})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{Depth: 6, MoveTime: 250 * time.Millisecond})
+		})
+		Convey("It should cap the depth", func() {
+			limits, err := parseSearchLimits("go", []string{"depth", "1000"})
+			So(err, ShouldBeNil)
+			So(limits.Depth, ShouldEqual, maxDepth)
+		})
+		Convey("It should fall back to defaultMoveTime without any limits", func() {
+			limits, err := parseSearchLimits("epd suite.epd", []string{})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{MoveTime: defaultMoveTime})
+		})
+		Convey("It should reject anything else", func() {
+			for _, args := range [][]string{{"depth"}, {"depth", "x"}, {"depth", "0"}, {"nodes", "100"}} {
+				_, err := parseSearchLimits("go", args)
+				So(err, ShouldHaveSameTypeAs, &errors.InvalidCommandError{})
+			}
+		})
+	})
+	Convey("Given parseGoLimits()", t, func() {
+		Convey("It should read depth and movetime", func() {
+			limits, err := parseGoLimits(board.White, []string{"depth", "6", "movetime", "250"})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{Depth: 6, MoveTime: 250 * time.Millisecond})
+		})
+		Convey("It should share out the side to move's time", func() {
+			args := []string{"wtime", "60000", "btime", "30000", "winc", "1000", "binc", "2000"}
+			limits, err := parseGoLimits(board.White, args)
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{MoveTime: 2500 * time.Millisecond})
+			limits, err = parseGoLimits(board.Black, append(args, "movestogo", "10"))
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{MoveTime: 4 * time.Second})
+		})
+		Convey("It should never use more than half the time left", func() {
+			limits, err := parseGoLimits(board.White, []string{"wtime", "100", "winc", "1000"})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{MoveTime: 50 * time.Millisecond})
+			limits, err = parseGoLimits(board.White, []string{"wtime", "0"})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{MoveTime: time.Millisecond})
+		})
+		Convey("It should skip parameters the search does not support", func() {
+			limits, err := parseGoLimits(board.White, []string{"searchmoves", "e2e4", "d2d4", "ponder", "depth", "3"})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{Depth: 3})
+		})
+		Convey("It should fall back to the default time", func() {
+			for _, args := range [][]string{{}, {"nodes", "1000"}, {"mate", "3"}, {"btime", "1000"}} {
+				limits, err := parseGoLimits(board.White, args)
+				So(err, ShouldBeNil)
+				So(limits, ShouldResemble, SearchLimits{MoveTime: defaultMoveTime})
+			}
+		})
+		Convey("It should search without a time limit on infinite", func() {
+			limits, err := parseGoLimits(board.White, []string{"infinite"})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, SearchLimits{Infinite: true})
+		})
+		Convey("It should report parameters without a number", func() {
+			limits, err := parseGoLimits(board.White, []string{"depth", "x", "movetime"})
+			So(err, ShouldResemble, &errors.InvalidCommandError{
+				Cmd: "go depth x movetime",
+				Err: "Expected a number after depth, movetime.",
+			})
+			So(limits, ShouldResemble, SearchLimits{MoveTime: defaultMoveTime})
+		})
+	})
+	Convey("Given an engine", t, func() {
+		ctx, ctxCancel := context.WithCancel(context.Background())
+		toEng, frmEng, _ := engine(ctx)
+		readUntilBestMove := func() []string {
+			lines := []string{}
+			for line := range frmEng {
+				lines = append(lines, line)
+				if strings.HasPrefix(line, "bestmove") {
+					break
+				}
+			}
+
+			return lines
+		}
+		Convey("go depth should report each depth then the best move", func() {
+			toEng <- "position fen k7/8/2K5/8/8/8/8/7R w - - 0 1"
+			toEng <- "go depth 4"
+			lines := readUntilBestMove()
+			So(lines, ShouldHaveLength, 4)
+			So(lines[0], ShouldStartWith, "info depth 1 score cp 500 pv ")
+			So(lines[2:], ShouldResemble, []string{
+				"info depth 3 score mate 2 pv c6b6 a8b8 h1h8",
+				"bestmove c6b6",
+			})
+		})
+		Convey("go movetime should stop in time", func() {
+			toEng <- "position startpos moves e2e4"
+			start := time.Now()
+			toEng <- "go movetime 100"
+			lines := readUntilBestMove()
+			So(time.Since(start), ShouldBeLessThan, 2*time.Second)
+			So(lines[len(lines)-2], ShouldStartWith, "info depth ")
+		})
+		Convey("go should report a null move when there is no move", func() {
+			toEng <- "position fen 4R1k1/5ppp/8/8/8/8/8/6K1 b - - 1 1"
+			toEng <- "go depth 2"
+			So(<-frmEng, ShouldEqual, "bestmove 0000")
+		})
+		Convey("go should report bad limits and still search", func() {
+			toEng <- "position startpos"
+			toEng <- "go depth x movetime 50"
+			lines := readUntilBestMove()
+			So(lines[0], ShouldEqual, "info string Error searching: Invalid command (go depth x movetime 50). "+
+				"Expected a number after depth.")
+			So(lines[len(lines)-1], ShouldStartWith, "bestmove ")
+		})
+		Convey("go should search with the time on the clock", func() {
+			toEng <- "position startpos moves e2e4"
+			start := time.Now()
+			toEng <- "go wtime 1000 btime 1000 winc 0 binc 0 movestogo 10"
+			lines := readUntilBestMove()
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+			So(lines[len(lines)-1], ShouldStartWith, "bestmove ")
+		})
+		Convey("go should reply with a best move to parameters the search does not support", func() {
+			toEng <- "position startpos"
+			toEng <- "go nodes 1000 searchmoves e2e4"
+			lines := readUntilBestMove()
+			So(lines[len(lines)-1], ShouldStartWith, "bestmove ")
+		})
+		Convey("go infinite should hold back the best move until stop", func() {
+			toEng <- "position startpos"
+			toEng <- "go infinite"
+			time.Sleep(100 * time.Millisecond)
+			toEng <- "isready"
+			lines := []string{}
+			for line := range frmEng {
+				lines = append(lines, line)
+				if line == "readyok\n" {
+					break
+				}
+			}
+			for _, line := range lines {
+				So(line, ShouldNotStartWith, "bestmove ")
+			}
+			toEng <- "stop"
+			lines = readUntilBestMove()
+			So(lines[len(lines)-1], ShouldStartWith, "bestmove ")
+		})
+		Convey("stop and ponderhit should be accepted without a search", func() {
+			toEng <- "stop"
+			toEng <- "ponderhit"
+			toEng <- "isready"
+			So(<-frmEng, ShouldEqual, "readyok\n")
+		})
+		Reset(ctxCancel)
+	})
+}
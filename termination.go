@@ -0,0 +1,179 @@
+package main
+
+import (
+	"math/bits"
+
+	"github.com/peteches/ChessEngine/board"
+)
+
+// Termination is the reason a game has ended, or Ongoing if it has not.
+type Termination uint8
+
+const (
+	Ongoing Termination = iota
+	Checkmate
+	Stalemate
+	InsufficientMaterial
+	FivefoldRepetition
+	SeventyFiveMoveRule
+	ThreefoldRepetition
+	FiftyMoveRule
+)
+
+func (t Termination) String() string {
+	switch t {
+	case Ongoing:
+		return "ongoing"
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case InsufficientMaterial:
+		return "insufficient material"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case SeventyFiveMoveRule:
+		return "seventy-five move rule"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FiftyMoveRule:
+		return "fifty move rule"
+	default:
+		return ""
+	}
+}
+
+// Claimable returns true for the draws a player must claim, rather than ones
+// which end the game straight away.
+func (t Termination) Claimable() bool {
+	return t == ThreefoldRepetition || t == FiftyMoveRule
+}
+
+// Result is the result of a game as written in PGN.
+type Result uint8
+
+const (
+	NoResult Result = iota
+	WhiteWins
+	BlackWins
+	Draw
+)
+
+func (r Result) String() string {
+	switch r {
+	case WhiteWins:
+		return "1-0"
+	case BlackWins:
+		return "0-1"
+	case Draw:
+		return "1/2-1/2"
+	case NoResult:
+		return "*"
+	default:
+		return "*"
+	}
+}
+
+// Outcome is the result of a game and why it ended.
+type Outcome struct {
+	Result Result
+	Reason Termination
+}
+
+const (
+	// fiftyMoveRulePlies is the number of plies without a capture or pawn
+	// move after which either player may claim a draw.
+	fiftyMoveRulePlies = 100
+	// seventyFiveMoveRulePlies is the number of plies without a capture
+	// or pawn move after which the game is drawn.
+	seventyFiveMoveRulePlies = 150
+	threefold                = 3
+	fivefold                 = 5
+)
+
+// darkSquares is a bitboard of the dark squares, A1 being dark.
+const darkSquares uint64 = 0xaa55aa55aa55aa55
+
+// Outcome returns whether the game is over and why. Claimable draws are
+// reported too, check Reason.Claimable() to tell them apart.
+func (p *Position) Outcome() Outcome {
+	if len(p.LegalMoves()) == 0 {
+		if !p.InCheck() {
+			return Outcome{Result: Draw, Reason: Stalemate}
+		}
+
+		if p.SideToMove == WHITE {
+			return Outcome{Result: BlackWins, Reason: Checkmate}
+		}
+
+		return Outcome{Result: WhiteWins, Reason: Checkmate}
+	}
+
+	repetitions := p.RepetitionCount()
+
+	switch {
+	case p.IsInsufficientMaterial():
+		return Outcome{Result: Draw, Reason: InsufficientMaterial}
+	case repetitions >= fivefold:
+		return Outcome{Result: Draw, Reason: FivefoldRepetition}
+	case p.HalfmoveClock >= seventyFiveMoveRulePlies:
+		return Outcome{Result: Draw, Reason: SeventyFiveMoveRule}
+	case repetitions >= threefold:
+		return Outcome{Result: Draw, Reason: ThreefoldRepetition}
+	case p.HalfmoveClock >= fiftyMoveRulePlies:
+		return Outcome{Result: Draw, Reason: FiftyMoveRule}
+	default:
+		return Outcome{Result: NoResult, Reason: Ongoing}
+	}
+}
+
+// IsCheckmate returns true if the side to move has been checkmated.
+func (p *Position) IsCheckmate() bool {
+	return p.InCheck() && len(p.LegalMoves()) == 0
+}
+
+// IsStalemate returns true if the side to move has no legal moves but is not
+// in check.
+func (p *Position) IsStalemate() bool {
+	return !p.InCheck() && len(p.LegalMoves()) == 0
+}
+
+// RepetitionCount returns how many times the current position has occurred,
+// including now, in the moves made since the position was set. Only positions
+// since the last capture or pawn move can repeat.
+func (p *Position) RepetitionCount() int {
+	count := 1
+
+	for idx := len(p.history) - 1; idx >= 0 && idx >= len(p.history)-int(p.HalfmoveClock); idx-- {
+		if p.history[idx].hash == p.hash {
+			count++
+		}
+	}
+
+	return count
+}
+
+// IsInsufficientMaterial returns true if neither side has enough material
+// left to checkmate: bare kings, a king and a single minor piece against a
+// bare king, or kings and bishops where every bishop is on the same colour.
+func (p *Position) IsInsufficientMaterial() bool {
+	heavy := p.Board.TypeOccupancy(board.PawnType).Board |
+		p.Board.TypeOccupancy(board.RookType).Board |
+		p.Board.TypeOccupancy(board.QueenType).Board
+	if heavy != 0 {
+		return false
+	}
+
+	knights := p.Board.TypeOccupancy(board.KnightType).Board
+	bishops := p.Board.TypeOccupancy(board.BishopType).Board
+	minors := bits.OnesCount64(knights | bishops)
+
+	switch {
+	case minors <= 1:
+		return true
+	case knights != 0:
+		return false
+	default:
+		return bishops&darkSquares == 0 || bishops&^darkSquares == 0
+	}
+}
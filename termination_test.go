@@ -0,0 +1,144 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//nolint:funlen // Convey testing is verbose
+func TestOutcome(t *testing.T) {
+	Convey("Given a Position", t, func() {
+		pos := NewPosition()
+		outcomeOf := func(fen string) Outcome {
+			So(pos.SetPositionFromFen(fen), ShouldBeNil)
+
+			return pos.Outcome()
+		}
+		playMoves := func(moves ...string) {
+			for _, move := range moves {
+				So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+			}
+		}
+		Convey("The starting position should be ongoing", func() {
+			So(outcomeOf(startingFen), ShouldResemble, Outcome{Result: NoResult, Reason: Ongoing})
+			So(pos.IsCheckmate(), ShouldBeFalse)
+			So(pos.IsStalemate(), ShouldBeFalse)
+		})
+		Convey("Checkmate should be a win for the side giving it", func() {
+			So(outcomeOf("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"), ShouldResemble,
+				Outcome{Result: BlackWins, Reason: Checkmate})
+			So(pos.IsCheckmate(), ShouldBeTrue)
+			So(outcomeOf("6k1/5ppp/8/8/8/8/8/K2R4 w - - 0 1"), ShouldResemble, Outcome{Result: NoResult, Reason: Ongoing})
+			playMoves("d1d8")
+			So(pos.Outcome(), ShouldResemble, Outcome{Result: WhiteWins, Reason: Checkmate})
+		})
+		Convey("Stalemate should be a draw", func() {
+			So(outcomeOf("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"), ShouldResemble, Outcome{Result: Draw, Reason: Stalemate})
+			So(pos.IsStalemate(), ShouldBeTrue)
+			So(pos.IsCheckmate(), ShouldBeFalse)
+		})
+		Convey("Checkmate should take precedence over the seventy-five move rule", func() {
+			So(outcomeOf("6k1/5ppp/8/8/8/8/8/K2R4 w - - 149 100"), ShouldResemble, Outcome{Result: Draw, Reason: FiftyMoveRule})
+			playMoves("d1d8")
+			So(pos.Outcome(), ShouldResemble, Outcome{Result: WhiteWins, Reason: Checkmate})
+		})
+		Convey("The halfmove clock should", func() {
+			Convey("allow a draw to be claimed after fifty moves", func() {
+				outcome := outcomeOf("4k3/8/8/8/8/8/8/R3K3 w - - 100 80")
+				So(outcome, ShouldResemble, Outcome{Result: Draw, Reason: FiftyMoveRule})
+				So(outcome.Reason.Claimable(), ShouldBeTrue)
+				So(outcomeOf("4k3/8/8/8/8/8/8/R3K3 w - - 99 80").Reason, ShouldEqual, Ongoing)
+			})
+			Convey("end the game after seventy-five moves", func() {
+				outcome := outcomeOf("4k3/8/8/8/8/8/8/R3K3 w - - 150 100")
+				So(outcome, ShouldResemble, Outcome{Result: Draw, Reason: SeventyFiveMoveRule})
+				So(outcome.Reason.Claimable(), ShouldBeFalse)
+			})
+		})
+		Convey("Repeating the position should", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			shuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+			Convey("count each occurrence of the current position", func() {
+				So(pos.RepetitionCount(), ShouldEqual, 1)
+				playMoves(shuffle...)
+				So(pos.RepetitionCount(), ShouldEqual, 2)
+				playMoves("g1f3")
+				So(pos.RepetitionCount(), ShouldEqual, 2)
+				pos.UnmakeMove()
+				So(pos.RepetitionCount(), ShouldEqual, 2)
+			})
+			Convey("allow a draw to be claimed on the third occurrence", func() {
+				playMoves(shuffle...)
+				So(pos.Outcome().Reason, ShouldEqual, Ongoing)
+				playMoves(shuffle...)
+				outcome := pos.Outcome()
+				So(outcome, ShouldResemble, Outcome{Result: Draw, Reason: ThreefoldRepetition})
+				So(outcome.Reason.Claimable(), ShouldBeTrue)
+			})
+			Convey("end the game on the fifth occurrence", func() {
+				for idx := 0; idx < 4; idx++ {
+					playMoves(shuffle...)
+				}
+				So(pos.RepetitionCount(), ShouldEqual, 5)
+				So(pos.Outcome(), ShouldResemble, Outcome{Result: Draw, Reason: FivefoldRepetition})
+			})
+			Convey("not count positions before a pawn move", func() {
+				playMoves(shuffle...)
+				playMoves("e2e3", "e7e6")
+				playMoves(shuffle...)
+				So(pos.RepetitionCount(), ShouldEqual, 2)
+			})
+			Convey("not count positions with different castling rights", func() {
+				So(pos.SetPositionFromFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), ShouldBeNil)
+				playMoves("e1f1", "e8f8", "f1e1", "f8e8")
+				So(pos.RepetitionCount(), ShouldEqual, 1)
+				playMoves("e1f1", "e8f8", "f1e1", "f8e8")
+				So(pos.RepetitionCount(), ShouldEqual, 2)
+			})
+		})
+		Convey("Insufficient material should be a draw with", func() {
+			insufficient := map[string]string{
+				"bare kings":                  "8/8/4k3/8/8/3K4/8/8 w - - 0 1",
+				"king and bishop v king":      "8/8/4k3/8/8/3K4/8/5B2 w - - 0 1",
+				"king and knight v king":      "8/8/4k3/8/8/3K4/8/5n2 b - - 0 1",
+				"bishops on the same colour":  "8/8/2b1k3/8/8/3K4/8/5B2 w - - 0 1",
+				"several same colour bishops": "8/8/2b1k3/8/8/3K4/4B3/5B2 w - - 0 1",
+			}
+			for name, fen := range insufficient {
+				fen := fen
+				Convey(name, func() {
+					So(outcomeOf(fen), ShouldResemble, Outcome{Result: Draw, Reason: InsufficientMaterial})
+					So(pos.IsInsufficientMaterial(), ShouldBeTrue)
+				})
+			}
+		})
+		Convey("Sufficient material should be ongoing with", func() {
+			sufficient := map[string]string{
+				"a pawn":                      "8/8/4k3/8/8/3K4/4P3/8 w - - 0 1",
+				"a rook":                      "8/8/4k3/8/8/3K4/8/5R2 w - - 0 1",
+				"a queen":                     "8/8/4k3/8/8/3K4/8/5q2 w - - 0 1",
+				"a knight each":               "8/8/2n1k3/8/8/3K4/8/5N2 w - - 0 1",
+				"two knights":                 "8/8/4k3/8/8/3K4/8/4NN2 w - - 0 1",
+				"bishop and knight":           "8/8/4k3/8/8/3K4/8/4NB2 w - - 0 1",
+				"bishops on opposite colours": "8/8/3bk3/8/8/3K4/8/5B2 w - - 0 1",
+				"one side's opposite bishops": "8/8/4k3/8/8/3K4/8/4BB2 w - - 0 1",
+			}
+			for name, fen := range sufficient {
+				fen := fen
+				Convey(name, func() {
+					So(outcomeOf(fen).Reason, ShouldEqual, Ongoing)
+					So(pos.IsInsufficientMaterial(), ShouldBeFalse)
+				})
+			}
+		})
+		Convey("Result and Termination should have readable names", func() {
+			So(WhiteWins.String(), ShouldEqual, "1-0")
+			So(BlackWins.String(), ShouldEqual, "0-1")
+			So(Draw.String(), ShouldEqual, "1/2-1/2")
+			So(NoResult.String(), ShouldEqual, "*")
+			So(Checkmate.String(), ShouldEqual, "checkmate")
+			So(SeventyFiveMoveRule.String(), ShouldEqual, "seventy-five move rule")
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/peteches/ChessEngine/board"
+	"github.com/peteches/ChessEngine/errors"
+)
+
+const (
+	maxPawns = 8
+	// maxCheckers is the most pieces which can give check at once, a
+	// discovered check along with the piece moved.
+	maxCheckers = 2
+)
+
+//nolint:gochecknoglobals // this is a pseudo const
+var sideNames = map[board.Side]string{
+	board.White: "white",
+	board.Black: "black",
+}
+
+// startingCounts are how many of each piece a side starts with, any more must
+// have come from promoting pawns.
+//
+//nolint:gochecknoglobals // this is a pseudo const
+var startingCounts = map[board.PieceType]int{
+	board.QueenType:  1,
+	board.RookType:   2,
+	board.BishopType: 2,
+	board.KnightType: 2,
+}
+
+// Validate checks the position could arise in a game: each side has one king,
+// no pawns are on the back ranks, neither side has more pieces than it could
+// have, the side not to move is not in check, the en passant target follows a
+// double pawn push and the castling rights match where the kings and rooks
+// are.
+func (p *Position) Validate() error {
+	for _, side := range []board.Side{board.White, board.Black} {
+		if err := p.validateMaterial(side); err != nil {
+			return err
+		}
+	}
+
+	backRanks := p.Board.TypeOccupancy(board.PawnType)
+	for backRanks.Board != 0 {
+		sqr := backRanks.PopSquare()
+		if sqr.Rank() == board.FirstRank || sqr.Rank() == board.EighthRank {
+			return &errors.PawnRankError{Fen: p.String(), Square: strings.ToLower(sqr.String())}
+		}
+	}
+
+	side := board.Side(p.SideToMove)
+
+	if p.Board.Checkers(opponent(side)).Board != 0 {
+		return &errors.CheckError{
+			Fen: p.String(),
+			Err: "The side not to move cannot be in check.",
+		}
+	}
+
+	if bits.OnesCount64(p.Board.Checkers(side).Board) > maxCheckers {
+		return &errors.CheckError{
+			Fen: p.String(),
+			Err: "The side to move cannot be in check from more than two pieces.",
+		}
+	}
+
+	if err := p.validateEnPassantTarget(); err != nil {
+		return err
+	}
+
+	return p.validateCastlingRights()
+}
+
+// validateEnPassantTarget checks the en passant target is the square a pawn
+// of the side not to move has just passed over with a double push.
+func (p *Position) validateEnPassantTarget() error {
+	target := p.EnPassantTarget
+	if target == 0 {
+		return nil
+	}
+
+	side := board.Side(p.SideToMove)
+	fail := func(err string) error {
+		return &errors.EnPassantError{Fen: p.String(), Square: strings.ToLower(target.String()), Err: err}
+	}
+
+	targetRank := board.SixthRank
+	if side == board.Black {
+		targetRank = board.ThirdRank
+	}
+
+	if target.Rank() != targetRank {
+		return fail("The en passant target must be on the sixth rank with white to move or the third with black to move.")
+	}
+
+	if !p.opponentPawns(side).BitBoard.Occupied(board.EnPassantCapture(side, target)) {
+		return fail("The pawn which just moved two squares must be in front of the en passant target.")
+	}
+
+	// the pawn's start square is behind the target from the pawn's side.
+	if p.Board.Occupied(target) || p.Board.Occupied(board.EnPassantCapture(opponent(side), target)) {
+		return fail("The en passant target and the square the pawn moved from must be empty.")
+	}
+
+	return nil
+}
+
+func (p *Position) validateMaterial(side board.Side) error {
+	count := func(pieceType board.PieceType) int {
+		return bits.OnesCount64(p.Board.PieceOfType(side, pieceType).Positions().Board)
+	}
+
+	if kings := count(board.KingType); kings != 1 {
+		return &errors.KingCountError{Fen: p.String(), Side: sideNames[side], Count: kings}
+	}
+
+	pawns := count(board.PawnType)
+	if pawns > maxPawns {
+		return &errors.PieceCountError{
+			Fen:  p.String(),
+			Side: sideNames[side],
+			Err:  "A side cannot have more than 8 pawns.",
+		}
+	}
+
+	promoted := 0
+
+	for pieceType, starting := range startingCounts {
+		if extra := count(pieceType) - starting; extra > 0 {
+			promoted += extra
+		}
+	}
+
+	if promoted > maxPawns-pawns {
+		return &errors.PieceCountError{
+			Fen:  p.String(),
+			Side: sideNames[side],
+			Err:  "There are more promoted pieces than missing pawns.",
+		}
+	}
+
+	return nil
+}
+
+// validateCastlingRights checks each castling right has its king and rook on
+// the back rank, with the rook on the side of the king it castles to.
+func (p *Position) validateCastlingRights() error {
+	for _, right := range allCastlingRights {
+		if p.CastlingRights&right == 0 {
+			continue
+		}
+
+		side := rightSide(right)
+		kingDst := castlingTargets[right].kingDst
+		kingSqr, rook := p.Board.KingSquare(side), p.castlingRook(right)
+		rooks := p.Board.PieceOfType(side, board.RookType).Positions()
+		backRank := kingDst.Rank()
+
+		valid := kingSqr != 0 && kingSqr.Rank() == backRank && rooks.Occupied(rook) &&
+			isKingSide(right) == (rook > kingSqr)
+
+		if !valid {
+			return &errors.CastlingPiecesError{Fen: p.String(), Right: castlingRightChars[right]}
+		}
+	}
+
+	return nil
+}
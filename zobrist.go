@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/peteches/ChessEngine/board"
+)
+
+// zobristPieceOrder gives the index of each piece in zobristKeys.pieces, by
+// its Fen character.
+const zobristPieceOrder = "KQBNRPkqbnrp"
+
+// zobristSeed is fixed so hashes are the same from run to run.
+const zobristSeed uint64 = 0x1d5f0a2c9b3e4785
+
+// zobristKeys holds the random numbers xored together to make a position's
+// hash. A position's hash is the xor of the key for each piece on its square,
+// blackToMove if it is black's turn, the key for the castling rights and the
+// key for the file of the en passant target if there is one.
+type zobristKeys struct {
+	pieces        [12][64]uint64
+	blackToMove   uint64
+	castling      [16]uint64
+	enPassantFile [8]uint64
+}
+
+//nolint:gochecknoglobals // this is a pseudo const
+var zobrist = newZobristKeys(zobristSeed)
+
+// splitMix64 is a small, fast pseudo random number generator, plenty good
+// enough for Zobrist keys.
+type splitMix64 uint64
+
+//nolint:gomnd // constants of the splitmix64 algorithm
+func (s *splitMix64) next() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return z ^ (z >> 31)
+}
+
+func newZobristKeys(seed uint64) *zobristKeys {
+	rng := splitMix64(seed)
+	keys := &zobristKeys{}
+
+	for piece := range keys.pieces {
+		for sqr := range keys.pieces[piece] {
+			keys.pieces[piece][sqr] = rng.next()
+		}
+	}
+
+	keys.blackToMove = rng.next()
+
+	// no castling rights leaves the hash alone
+	for rights := 1; rights < len(keys.castling); rights++ {
+		keys.castling[rights] = rng.next()
+	}
+
+	for file := range keys.enPassantFile {
+		keys.enPassantFile[file] = rng.next()
+	}
+
+	return keys
+}
+
+func (z *zobristKeys) piece(piece board.Piece, sqr board.Square) uint64 {
+	return z.pieces[strings.Index(zobristPieceOrder, piece.String())][sqr.Index()]
+}
+
+func (z *zobristKeys) enPassant(target board.Square) uint64 {
+	if target == 0 {
+		return 0
+	}
+
+	//nolint:gomnd // 8 squares to a rank
+	return z.enPassantFile[target.Index()%8]
+}
+
+// Hash returns the Zobrist hash of the position, kept up to date as moves are
+// made and unmade.
+func (p *Position) Hash() uint64 {
+	return p.hash
+}
+
+// ComputeHash calculates the Zobrist hash of the position from scratch. It
+// should always agree with Hash.
+func (p *Position) ComputeHash() uint64 {
+	var hash uint64
+
+	for _, side := range []board.Side{board.White, board.Black} {
+		for _, piece := range p.Board.Pieces(side) {
+			for _, sqr := range piece.Positions().Squares() {
+				hash ^= zobrist.piece(piece, sqr)
+			}
+		}
+	}
+
+	if p.SideToMove == BLACK {
+		hash ^= zobrist.blackToMove
+	}
+
+	hash ^= zobrist.castling[p.CastlingRights]
+	hash ^= zobrist.enPassant(p.EnPassantTarget)
+
+	return hash
+}
+
+// flip adds or removes piece from sqr, updating the hash to match.
+func (p *Position) flip(piece board.Piece, sqr board.Square) {
+	p.Board.Flip(piece, sqr)
+	p.hash ^= zobrist.piece(piece, sqr)
+}
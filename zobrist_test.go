@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// walkHashes plays every line to depth from pos, calling check at each node.
+func walkHashes(pos *Position, depth int, check func(*Position)) {
+	check(pos)
+
+	if depth == 0 {
+		return
+	}
+
+	for _, move := range pos.LegalMoves() {
+		pos.play(move)
+		walkHashes(pos, depth-1, check)
+		pos.UnmakeMove()
+	}
+}
+
+//nolint:funlen // Convey testing is verbose
+func TestZobrist(t *testing.T) {
+	Convey("Given a Position", t, func() {
+		pos := NewPosition()
+		Convey("An empty position with white to move should hash to 0", func() {
+			So(pos.Hash(), ShouldEqual, 0)
+			So(pos.ComputeHash(), ShouldEqual, 0)
+		})
+		Convey("SetPositionFromFen() should set the hash", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			So(pos.Hash(), ShouldNotEqual, 0)
+			So(pos.Hash(), ShouldEqual, pos.ComputeHash())
+		})
+		Convey("The hash should differ when only", func() {
+			hashOf := func(fen string) uint64 {
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+
+				return pos.Hash()
+			}
+			start := hashOf(startingFen)
+			Convey("the side to move differs", func() {
+				So(hashOf("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1"), ShouldNotEqual, start)
+			})
+			Convey("the castling rights differ", func() {
+				So(hashOf("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQk - 0 1"), ShouldNotEqual, start)
+			})
+			Convey("the en passant target differs", func() {
+				So(hashOf("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"), ShouldNotEqual,
+					hashOf("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"))
+			})
+			Convey("But not when only the move counters differ", func() {
+				So(hashOf("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 7 12"), ShouldEqual, start)
+			})
+		})
+		Convey("Transposed move orders should reach the same hash", func() {
+			So(pos.SetPositionFromFen(startingFen), ShouldBeNil)
+			for _, move := range []string{"g1f3", "g8f6", "b1c3"} {
+				So(pos.MakeMove(mustParseMove(move)), ShouldBeNil)
+			}
+			transposed := NewPosition()
+			So(transposed.SetPositionFromFen(startingFen), ShouldBeNil)
+			for _, move := range []string{"b1c3", "g8f6", "g1f3"} {
+				So(transposed.MakeMove(mustParseMove(move)), ShouldBeNil)
+			}
+			So(pos.Hash(), ShouldEqual, transposed.Hash())
+		})
+		Convey("Make and unmake should keep the hash in step with ComputeHash()", func() {
+			fens := []string{
+				startingFen,
+				"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+				"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+				"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			}
+			for _, fen := range fens {
+				So(pos.SetPositionFromFen(fen), ShouldBeNil)
+				original := pos.Hash()
+				mismatches := 0
+				walkHashes(pos, 2, func(node *Position) {
+					if node.Hash() != node.ComputeHash() {
+						mismatches++
+					}
+				})
+				So(mismatches, ShouldEqual, 0)
+				So(pos.Hash(), ShouldEqual, original)
+			}
+		})
+	})
+}